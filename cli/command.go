@@ -0,0 +1,333 @@
+// Package cli runs a tree of commands, such as "deploy create", each with
+// its own flags, and generates their help and shell completion scripts.
+//
+// Resources plug into the tree by adding their own command group to the
+// root command:
+//
+//	root := &cli.Command{Name: "tool"}
+//	root.Add(&cli.Command{
+//		Name:  "deploy",
+//		Short: "Manage deployments",
+//	}).Add(&cli.Command{
+//		Name:  "delete",
+//		Short: "Delete a deployment",
+//		Args:  "NAME",
+//		Run:   func(args []string) error { ... },
+//	})
+//	err := root.Execute(os.Args[1:])
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+)
+
+// Command is a node of a command tree. A command either runs, when Run is
+// set, or groups subcommands.
+type Command struct {
+	// Name is the word selecting the command. The root command is named
+	// after the program.
+	Name string
+
+	// Aliases are other words selecting the command, e.g. "svc" for
+	// "service".
+	Aliases []string
+
+	// Short is a one-line description, shown in the help of the parent.
+	Short string
+
+	// Long is an optional description shown in the command's own help.
+	Long string
+
+	// Args describes the positional arguments in the usage line, e.g.
+	// "NAME" or "[-- COMMAND...]".
+	Args string
+
+	// Flags registers the flags of the command.
+	Flags func(flags *flag.FlagSet)
+
+	// PersistentFlags registers flags accepted by the command and every
+	// command below it.
+	PersistentFlags func(flags *flag.FlagSet)
+
+	// Run runs the command with the positional arguments left once the
+	// flags are parsed. Flags and positional arguments may be mixed, but
+	// everything after "--" is positional.
+	Run func(args []string) error
+
+	Commands []*Command
+
+	parent *Command
+}
+
+// UsageError reports a command line the command cannot run with.
+type UsageError struct {
+	Command string
+	Message string
+}
+
+func (e *UsageError) Error() string {
+	return fmt.Sprintf("%s (run '%s -help' for usage)", e.Message, e.Command)
+}
+
+// Usagef builds the error a Run function returns to reject its arguments.
+// Execute fills in the command.
+func Usagef(format string, args ...interface{}) error {
+	return &UsageError{Message: fmt.Sprintf(format, args...)}
+}
+
+// Add adds subcommands and returns the last one, so a group and its
+// commands can be added in one go.
+func (c *Command) Add(commands ...*Command) *Command {
+	for _, command := range commands {
+		command.parent = c
+		c.Commands = append(c.Commands, command)
+	}
+
+	if len(commands) == 0 {
+		return c
+	}
+
+	return commands[len(commands)-1]
+}
+
+// Path returns the words selecting the command, starting with the program
+// name.
+func (c *Command) Path() string {
+	if c.parent == nil {
+		return c.Name
+	}
+
+	return c.parent.Path() + " " + c.Name
+}
+
+// Execute runs the command selected by the leading words of args with the
+// rest of them. -h and -help print the help of the command instead.
+func (c *Command) Execute(args []string) error {
+	command, args := c.Find(args)
+
+	flags := command.flagSet()
+	positional, err := parseFlags(flags, args)
+	if err == flag.ErrHelp {
+		command.PrintHelp(os.Stdout)
+		return nil
+	}
+	if err != nil {
+		return &UsageError{Command: command.Path(), Message: err.Error()}
+	}
+
+	if command.Run == nil {
+		if len(positional) > 0 {
+			return &UsageError{Command: command.Path(), Message: fmt.Sprintf("unknown command %q", positional[0])}
+		}
+
+		command.PrintHelp(os.Stdout)
+		return &UsageError{Command: command.Path(), Message: "a command is required"}
+	}
+
+	err = command.Run(positional)
+	if usageErr, ok := err.(*UsageError); ok && usageErr.Command == "" {
+		usageErr.Command = command.Path()
+	}
+
+	return err
+}
+
+// Find returns the command selected by the leading words of args and the
+// arguments left after them. Persistent flags may come before the words,
+// as in "tool -namespace x deploy list"; they are skipped over and
+// returned first among the arguments left.
+func (c *Command) Find(args []string) (*Command, []string) {
+	command := c
+	flagArgs := []string{}
+	for len(args) > 0 {
+		if n := command.persistentFlagArgs(args); n > 0 {
+			flagArgs, args = append(flagArgs, args[:n]...), args[n:]
+			continue
+		}
+
+		sub := command.subcommand(args[0])
+		if sub == nil {
+			break
+		}
+
+		command, args = sub, args[1:]
+	}
+
+	return command, append(flagArgs, args...)
+}
+
+// persistentFlagArgs returns how many of the leading args are a persistent
+// flag of the command or its ancestors and its value, or 0 when args do
+// not start with one.
+func (c *Command) persistentFlagArgs(args []string) int {
+	name := args[0]
+	if len(name) < 2 || name[0] != '-' || name == "--" {
+		return 0
+	}
+
+	name = strings.TrimPrefix(name[1:], "-")
+	inlineValue := false
+	if i := strings.Index(name, "="); i >= 0 {
+		name, inlineValue = name[:i], true
+	}
+
+	f := c.persistentFlagSet().Lookup(name)
+	if f == nil {
+		return 0
+	}
+
+	if inlineValue || len(args) == 1 {
+		return 1
+	}
+
+	if value, ok := f.Value.(boolFlag); ok && value.IsBoolFlag() {
+		return 1
+	}
+
+	return 2
+}
+
+// boolFlag is implemented by the values of flags given without a value,
+// such as -dry-run.
+type boolFlag interface {
+	flag.Value
+	IsBoolFlag() bool
+}
+
+func (c *Command) subcommand(word string) *Command {
+	for _, sub := range c.Commands {
+		if sub.Name == word {
+			return sub
+		}
+
+		for _, alias := range sub.Aliases {
+			if alias == word {
+				return sub
+			}
+		}
+	}
+
+	return nil
+}
+
+// flagSet builds the flags of the command, its ancestors' persistent flags
+// included.
+func (c *Command) flagSet() *flag.FlagSet {
+	flags := c.persistentFlagSet()
+	if c.Flags != nil {
+		c.Flags(flags)
+	}
+
+	return flags
+}
+
+// persistentFlagSet builds the persistent flags of the command and its
+// ancestors.
+func (c *Command) persistentFlagSet() *flag.FlagSet {
+	flags := flag.NewFlagSet(c.Path(), flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.Usage = func() {}
+
+	for ancestor := c; ancestor != nil; ancestor = ancestor.parent {
+		if ancestor.PersistentFlags != nil {
+			ancestor.PersistentFlags(flags)
+		}
+	}
+
+	return flags
+}
+
+// parseFlags parses flags wherever they appear among the positional
+// arguments, which it returns, up to a "--" argument.
+func parseFlags(flags *flag.FlagSet, args []string) ([]string, error) {
+	positional := []string{}
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, err
+		}
+
+		consumed := len(args) - flags.NArg()
+		if consumed > 0 && args[consumed-1] == "--" || flags.NArg() == 0 {
+			return append(positional, flags.Args()...), nil
+		}
+
+		positional = append(positional, flags.Arg(0))
+		args = flags.Args()[1:]
+	}
+}
+
+// PrintHelp prints the description, usage, subcommands and flags of the
+// command.
+func (c *Command) PrintHelp(w io.Writer) {
+	description := c.Long
+	if description == "" {
+		description = c.Short
+	}
+	if description != "" {
+		fmt.Fprintf(w, "%s\n\n", description)
+	}
+
+	usage := c.Path()
+	if len(c.Commands) > 0 {
+		usage += " COMMAND"
+	}
+	usage += " [flags]"
+	if c.Args != "" {
+		usage += " " + c.Args
+	}
+	fmt.Fprintf(w, "Usage:\n  %s\n", usage)
+
+	if len(c.Aliases) > 0 {
+		fmt.Fprintf(w, "\nAliases:\n  %s\n", strings.Join(append([]string{c.Name}, c.Aliases...), ", "))
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintln(w, "\nCommands:")
+		width := 0
+		for _, sub := range c.Commands {
+			if len(sub.Name) > width {
+				width = len(sub.Name)
+			}
+		}
+		for _, sub := range c.Commands {
+			fmt.Fprintf(w, "  %-*s  %s\n", width, sub.Name, sub.Short)
+		}
+	}
+
+	flags := c.flagSet()
+	hasFlags := false
+	flags.VisitAll(func(*flag.Flag) { hasFlags = true })
+	if hasFlags {
+		fmt.Fprintln(w, "\nFlags:")
+		flags.SetOutput(w)
+		flags.PrintDefaults()
+	}
+
+	if len(c.Commands) > 0 {
+		fmt.Fprintf(w, "\nRun '%s COMMAND -help' for more information on a command.\n", c.Path())
+	}
+}
+
+// NewHelpCommand returns a "help" command printing the help of the command
+// its arguments select below root.
+func NewHelpCommand(root *Command) *Command {
+	return &Command{
+		Name:  "help",
+		Short: "Show the help of a command",
+		Args:  "[COMMAND...]",
+		Run: func(args []string) error {
+			command, rest := root.Find(args)
+			if len(rest) > 0 {
+				return Usagef("unknown command %q", strings.Join(args, " "))
+			}
+
+			command.PrintHelp(os.Stdout)
+			return nil
+		},
+	}
+}
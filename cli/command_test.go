@@ -0,0 +1,192 @@
+package cli
+
+import (
+	"flag"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// testInvocation records what a command of the test tree ran with.
+type testInvocation struct {
+	command   string
+	namespace string
+	verbose   bool
+	image     string
+	dryRun    bool
+	args      []string
+}
+
+// newTestTree builds "tool deploy create|list", with a -namespace and a
+// -verbose persistent flag on the root, and records the command run.
+func newTestTree(ran *testInvocation) *Command {
+	var namespace, image string
+	var verbose, dryRun bool
+
+	run := func(command *Command) func([]string) error {
+		return func(args []string) error {
+			*ran = testInvocation{
+				command:   command.Path(),
+				namespace: namespace,
+				verbose:   verbose,
+				image:     image,
+				dryRun:    dryRun,
+				args:      args,
+			}
+			return nil
+		}
+	}
+
+	root := &Command{
+		Name: "tool",
+		PersistentFlags: func(flags *flag.FlagSet) {
+			flags.StringVar(&namespace, "namespace", "default", "Namespace to use")
+			flags.BoolVar(&verbose, "verbose", false, "Print more")
+		},
+	}
+
+	deploy := root.Add(&Command{
+		Name:    "deploy",
+		Aliases: []string{"deployment"},
+		Short:   "Manage deployments",
+	})
+
+	create := &Command{
+		Name:  "create",
+		Short: "Create a deployment",
+		Args:  "NAME",
+		Flags: func(flags *flag.FlagSet) {
+			flags.StringVar(&image, "image", "", "Image to run")
+			flags.BoolVar(&dryRun, "dry-run", false, "Only print the deployment")
+		},
+	}
+	create.Run = run(create)
+
+	list := &Command{Name: "list", Short: "List deployments"}
+	list.Run = run(list)
+
+	deploy.Add(create, list)
+	return root
+}
+
+func TestExecute(t *testing.T) {
+	tests := []struct {
+		args []string
+		want testInvocation
+	}{
+		{
+			args: []string{"deploy", "create", "-image", "nginx", "web"},
+			want: testInvocation{command: "tool deploy create", namespace: "default", image: "nginx", args: []string{"web"}},
+		},
+		{
+			args: []string{"-namespace", "prod", "deploy", "create", "web"},
+			want: testInvocation{command: "tool deploy create", namespace: "prod", args: []string{"web"}},
+		},
+		{
+			args: []string{"--namespace=prod", "deploy", "list"},
+			want: testInvocation{command: "tool deploy list", namespace: "prod", args: []string{}},
+		},
+		{
+			args: []string{"deploy", "-namespace", "prod", "create", "web"},
+			want: testInvocation{command: "tool deploy create", namespace: "prod", args: []string{"web"}},
+		},
+		{
+			args: []string{"deploy", "create", "web", "-namespace=prod"},
+			want: testInvocation{command: "tool deploy create", namespace: "prod", args: []string{"web"}},
+		},
+		{
+			args: []string{"deploy", "create", "-dry-run", "web", "-image", "nginx", "extra"},
+			want: testInvocation{command: "tool deploy create", namespace: "default", image: "nginx", dryRun: true, args: []string{"web", "extra"}},
+		},
+		{
+			// A bool flag must not take the next word as its value.
+			args: []string{"-verbose", "deploy", "list"},
+			want: testInvocation{command: "tool deploy list", namespace: "default", verbose: true, args: []string{}},
+		},
+		{
+			args: []string{"-verbose=false", "-namespace", "prod", "deploy", "-verbose", "list"},
+			want: testInvocation{command: "tool deploy list", namespace: "prod", verbose: true, args: []string{}},
+		},
+		{
+			args: []string{"deployment", "create", "web"},
+			want: testInvocation{command: "tool deploy create", namespace: "default", args: []string{"web"}},
+		},
+		{
+			args: []string{"deploy", "create", "web", "--", "-image", "nginx"},
+			want: testInvocation{command: "tool deploy create", namespace: "default", args: []string{"web", "-image", "nginx"}},
+		},
+		{
+			args: []string{"deploy", "create", "--", "--", "list"},
+			want: testInvocation{command: "tool deploy create", namespace: "default", args: []string{"--", "list"}},
+		},
+	}
+
+	for _, test := range tests {
+		var ran testInvocation
+		if err := newTestTree(&ran).Execute(test.args); err != nil {
+			t.Errorf("Execute(%q): %v", test.args, err)
+			continue
+		}
+
+		if !reflect.DeepEqual(ran, test.want) {
+			t.Errorf("Execute(%q): ran %+v, want %+v", test.args, ran, test.want)
+		}
+	}
+}
+
+func TestExecuteUsageErrors(t *testing.T) {
+	tests := []struct {
+		args    []string
+		command string
+		message string
+	}{
+		{[]string{"deploy", "scale"}, "tool deploy", `unknown command "scale"`},
+		{[]string{"deploy", "create", "-replicas", "3"}, "tool deploy create", "-replicas"},
+		{[]string{"deploy", "list", "-namespace"}, "tool deploy list", "-namespace"},
+		{[]string{"-image", "nginx", "deploy", "create"}, "tool", "-image"},
+	}
+
+	for _, test := range tests {
+		var ran testInvocation
+		err := newTestTree(&ran).Execute(test.args)
+		usageErr, ok := err.(*UsageError)
+		if !ok {
+			t.Errorf("Execute(%q): got %v, want a usage error", test.args, err)
+			continue
+		}
+
+		if usageErr.Command != test.command || !strings.Contains(usageErr.Message, test.message) {
+			t.Errorf("Execute(%q): got %q from %q, want a message about %s from %q",
+				test.args, usageErr.Message, usageErr.Command, test.message, test.command)
+		}
+
+		if ran.command != "" {
+			t.Errorf("Execute(%q) ran %s", test.args, ran.command)
+		}
+	}
+}
+
+func TestFind(t *testing.T) {
+	tests := []struct {
+		args    []string
+		command string
+		rest    []string
+	}{
+		{nil, "tool", []string{}},
+		{[]string{"deploy"}, "tool deploy", []string{}},
+		{[]string{"deployment", "list", "-o", "json"}, "tool deploy list", []string{"-o", "json"}},
+		{[]string{"-namespace", "prod", "deploy", "create", "web"}, "tool deploy create", []string{"-namespace", "prod", "web"}},
+		{[]string{"-verbose", "deploy", "list"}, "tool deploy list", []string{"-verbose"}},
+		{[]string{"-namespace"}, "tool", []string{"-namespace"}},
+		{[]string{"deploy", "scale", "list"}, "tool deploy", []string{"scale", "list"}},
+		{[]string{"--", "deploy"}, "tool", []string{"--", "deploy"}},
+	}
+
+	for _, test := range tests {
+		var ran testInvocation
+		command, rest := newTestTree(&ran).Find(test.args)
+		if command.Path() != test.command || !reflect.DeepEqual(rest, test.rest) {
+			t.Errorf("Find(%q) = %q, %q, want %q, %q", test.args, command.Path(), rest, test.command, test.rest)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package cli
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// completionNode is what the completion scripts know about a command: the
+// words selecting it, its subcommands and its flags.
+type completionNode struct {
+	command *Command
+	path    string
+	words   []string
+	flags   []*flag.Flag
+}
+
+// completionNodes walks the tree below root. Paths leave out the program
+// name and start with a space, e.g. " deploy create"; the root's is empty.
+func completionNodes(root *Command) []completionNode {
+	nodes := []completionNode{}
+
+	var walk func(command *Command, path string)
+	walk = func(command *Command, path string) {
+		node := completionNode{command: command, path: path}
+		for _, sub := range command.Commands {
+			node.words = append(node.words, sub.Name)
+		}
+
+		command.flagSet().VisitAll(func(f *flag.Flag) {
+			node.flags = append(node.flags, f)
+		})
+		sort.Slice(node.flags, func(i, j int) bool {
+			return node.flags[i].Name < node.flags[j].Name
+		})
+
+		nodes = append(nodes, node)
+		for _, sub := range command.Commands {
+			walk(sub, path+" "+sub.Name)
+		}
+	}
+
+	walk(root, "")
+	return nodes
+}
+
+// names returns the name and aliases of the command.
+func names(command *Command) []string {
+	return append([]string{command.Name}, command.Aliases...)
+}
+
+var nonIdentifier = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+// functionName turns the program name into a shell function name.
+func functionName(program string) string {
+	return "_" + nonIdentifier.ReplaceAllString(program, "_")
+}
+
+// GenBashCompletion writes a bash completion script for the command tree.
+func (c *Command) GenBashCompletion(w io.Writer) error {
+	nodes := completionNodes(c)
+	function := functionName(c.Name)
+
+	var script bytes.Buffer
+	fmt.Fprintf(&script, "# bash completion for %s, generated by %q.\n", c.Name, c.Name+" completion bash")
+	fmt.Fprintf(&script, "%s() {\n", function)
+	fmt.Fprintln(&script, `    local cur="${COMP_WORDS[COMP_CWORD]}" path="" words="" i`)
+	fmt.Fprintln(&script, `    for ((i = 1; i < COMP_CWORD; i++)); do`)
+	fmt.Fprintln(&script, `        case "${path} ${COMP_WORDS[i]}" in`)
+	for _, node := range nodes[1:] {
+		parent := strings.TrimSuffix(node.path, " "+node.command.Name)
+		patterns := []string{}
+		for _, name := range names(node.command) {
+			patterns = append(patterns, fmt.Sprintf("%q", parent+" "+name))
+		}
+		fmt.Fprintf(&script, "            %s) path=%q ;;\n", strings.Join(patterns, "|"), node.path)
+	}
+	fmt.Fprintln(&script, `        esac`)
+	fmt.Fprintln(&script, `    done`)
+	fmt.Fprintln(&script, `    case "${path}" in`)
+	for _, node := range nodes {
+		words := append([]string{}, node.words...)
+		for _, f := range node.flags {
+			words = append(words, "-"+f.Name)
+		}
+		fmt.Fprintf(&script, "        %q) words=%q ;;\n", node.path, strings.Join(words, " "))
+	}
+	fmt.Fprintln(&script, `    esac`)
+	fmt.Fprintln(&script, `    COMPREPLY=($(compgen -W "${words}" -- "${cur}"))`)
+	fmt.Fprintln(&script, `}`)
+	fmt.Fprintf(&script, "complete -o default -F %s %s\n", function, c.Name)
+
+	_, err := script.WriteTo(w)
+	return err
+}
+
+// GenZshCompletion writes a zsh completion script for the command tree. It
+// runs the bash script through zsh's bash completion emulation.
+func (c *Command) GenZshCompletion(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "#compdef %s\n\nautoload -U +X bashcompinit && bashcompinit\n\n", c.Name); err != nil {
+		return err
+	}
+
+	return c.GenBashCompletion(w)
+}
+
+// GenFishCompletion writes a fish completion script for the command tree.
+func (c *Command) GenFishCompletion(w io.Writer) error {
+	nodes := completionNodes(c)
+	function := functionName(c.Name)
+
+	var script bytes.Buffer
+	fmt.Fprintf(&script, "# fish completion for %s, generated by %q.\n", c.Name, c.Name+" completion fish")
+	fmt.Fprintf(&script, "function %s_path\n", function)
+	fmt.Fprintln(&script, `    set -l words (commandline -opc)`)
+	fmt.Fprintln(&script, `    set -e words[1]`)
+	fmt.Fprintln(&script, `    set -l path ""`)
+	fmt.Fprintln(&script, `    for word in $words`)
+	fmt.Fprintln(&script, `        switch "$path $word"`)
+	for _, node := range nodes[1:] {
+		parent := strings.TrimSuffix(node.path, " "+node.command.Name)
+		patterns := []string{}
+		for _, name := range names(node.command) {
+			patterns = append(patterns, fishQuote(parent+" "+name))
+		}
+		fmt.Fprintf(&script, "            case %s\n", strings.Join(patterns, " "))
+		fmt.Fprintf(&script, "                set path %s\n", fishQuote(node.path))
+	}
+	fmt.Fprintln(&script, `        end`)
+	fmt.Fprintln(&script, `    end`)
+	fmt.Fprintln(&script, `    echo "$path"`)
+	fmt.Fprintln(&script, `end`)
+	fmt.Fprintln(&script)
+	fmt.Fprintf(&script, "function %s_at\n", function)
+	fmt.Fprintf(&script, "    test (%s_path) = \"$argv[1]\"\n", function)
+	fmt.Fprintln(&script, `end`)
+	fmt.Fprintln(&script)
+	fmt.Fprintf(&script, "complete -c %s -f\n", c.Name)
+	for _, node := range nodes {
+		condition := fishQuote(function + "_at " + fishQuote(node.path))
+		for _, sub := range node.command.Commands {
+			fmt.Fprintf(&script, "complete -c %s -n %s -a %s -d %s\n", c.Name, condition, sub.Name, fishQuote(sub.Short))
+		}
+		for _, f := range node.flags {
+			fmt.Fprintf(&script, "complete -c %s -n %s -o %s -d %s\n", c.Name, condition, f.Name, fishQuote(firstLine(f.Usage)))
+		}
+	}
+
+	_, err := script.WriteTo(w)
+	return err
+}
+
+// fishQuote quotes a word for fish, which only escapes \ and ' inside
+// single quotes.
+func fishQuote(word string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(word) + "'"
+}
+
+func firstLine(text string) string {
+	return strings.SplitN(text, "\n", 2)[0]
+}
+
+// NewCompletionCommand returns a "completion" command printing the
+// completion script of root for the shell given as argument.
+func NewCompletionCommand(root *Command) *Command {
+	return &Command{
+		Name:  "completion",
+		Short: "Print a shell completion script",
+		Long: "Print a shell completion script, e.g.\n\n" +
+			"  source <(" + root.Name + " completion bash)\n" +
+			"  " + root.Name + " completion fish > ~/.config/fish/completions/" + root.Name + ".fish",
+		Args: "bash | zsh | fish",
+		Run: func(args []string) error {
+			if len(args) != 1 {
+				return Usagef("a shell is required: bash | zsh | fish")
+			}
+
+			switch args[0] {
+			case "bash":
+				return root.GenBashCompletion(os.Stdout)
+			case "zsh":
+				return root.GenZshCompletion(os.Stdout)
+			case "fish":
+				return root.GenFishCompletion(os.Stdout)
+			default:
+				return Usagef("unknown shell %q, must be: bash | zsh | fish", args[0])
+			}
+		},
+	}
+}
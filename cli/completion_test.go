@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestCompletion(t *testing.T) {
+	var ran testInvocation
+	root := newTestTree(&ran)
+
+	tests := []struct {
+		shell    string
+		generate func(*bytes.Buffer) error
+		prefix   string
+		lines    []string
+	}{
+		{
+			shell:    "bash",
+			generate: func(w *bytes.Buffer) error { return root.GenBashCompletion(w) },
+			prefix:   "# bash completion for tool",
+			lines: []string{
+				`_tool() {`,
+				`            " deploy"|" deployment") path=" deploy" ;;`,
+				`            " deploy create") path=" deploy create" ;;`,
+				`        "") words="deploy -namespace -verbose" ;;`,
+				`        " deploy") words="create list -namespace -verbose" ;;`,
+				`        " deploy create") words="-dry-run -image -namespace -verbose" ;;`,
+				`complete -o default -F _tool tool`,
+			},
+		},
+		{
+			shell:    "zsh",
+			generate: func(w *bytes.Buffer) error { return root.GenZshCompletion(w) },
+			prefix:   "#compdef tool\n",
+			lines: []string{
+				`autoload -U +X bashcompinit && bashcompinit`,
+				`        " deploy") words="create list -namespace -verbose" ;;`,
+				`complete -o default -F _tool tool`,
+			},
+		},
+		{
+			shell:    "fish",
+			generate: func(w *bytes.Buffer) error { return root.GenFishCompletion(w) },
+			prefix:   "# fish completion for tool",
+			lines: []string{
+				`            case ' deploy' ' deployment'`,
+				`                set path ' deploy'`,
+				`complete -c tool -f`,
+				`complete -c tool -n '_tool_at \'\'' -a deploy -d 'Manage deployments'`,
+				`complete -c tool -n '_tool_at \' deploy\'' -a create -d 'Create a deployment'`,
+				`complete -c tool -n '_tool_at \' deploy create\'' -o dry-run -d 'Only print the deployment'`,
+				`complete -c tool -n '_tool_at \' deploy list\'' -o namespace -d 'Namespace to use'`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		var script bytes.Buffer
+		if err := test.generate(&script); err != nil {
+			t.Errorf("%s completion: %v", test.shell, err)
+			continue
+		}
+
+		if !strings.HasPrefix(script.String(), test.prefix) {
+			t.Errorf("%s completion: got a script starting with %q, want %q", test.shell, firstLine(script.String()), test.prefix)
+		}
+
+		lines := strings.Split(script.String(), "\n")
+		for _, want := range test.lines {
+			if !containsLine(lines, want) {
+				t.Errorf("%s completion: missing line %q in\n%s", test.shell, want, script.String())
+			}
+		}
+	}
+}
+
+func TestFishQuote(t *testing.T) {
+	tests := map[string]string{
+		"":        "''",
+		" deploy": "' deploy'",
+		"it's":    `'it\'s'`,
+		`C:\tmp`:  `'C:\\tmp'`,
+		`'\'`:     `'\'\\\''`,
+	}
+
+	for word, want := range tests {
+		if got := fishQuote(word); got != want {
+			t.Errorf("fishQuote(%q) = %s, want %s", word, got, want)
+		}
+	}
+}
+
+func containsLine(lines []string, want string) bool {
+	for _, line := range lines {
+		if line == want {
+			return true
+		}
+	}
+
+	return false
+}
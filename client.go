@@ -0,0 +1,62 @@
+package main
+
+import (
+	"flag"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// clientFlags select the cluster to talk to, as the same kubectl flags do.
+// Every command accepts them.
+type clientFlags struct {
+	kubeconfig string
+	overrides  clientcmd.ConfigOverrides
+}
+
+func (f *clientFlags) register(flags *flag.FlagSet) {
+	flags.StringVar(&f.kubeconfig, "kubeconfig", "", "Path to the kubeconfig file (default: $KUBECONFIG, or ~/.kube/config)")
+	flags.StringVar(&f.overrides.CurrentContext, "context", "", "Kubeconfig context to use (default: the current context)")
+	flags.StringVar(&f.overrides.Context.Cluster, "cluster", "", "Kubeconfig cluster to use instead of the context's one")
+	flags.StringVar(&f.overrides.Context.AuthInfo, "user", "", "Kubeconfig user to use instead of the context's one")
+	flags.StringVar(&f.overrides.Context.Namespace, "namespace", "", "Namespace to operate in (default: the context's namespace, or default)")
+}
+
+// client builds the client set and returns it with the namespace to
+// operate in. The configuration comes from the kubeconfig files, merged
+// the way kubectl merges them, and the overriding flags. Inside a pod with
+// no kubeconfig around, it comes from the pod's service account instead.
+func (f *clientFlags) client() (kubernetes.Interface, string, error) {
+	config, namespace, err := f.config()
+	if err != nil {
+		return nil, "", err
+	}
+
+	kubernetesClientSet, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return kubernetesClientSet, namespace, nil
+}
+
+func (f *clientFlags) config() (*rest.Config, string, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.ExplicitPath = f.kubeconfig
+
+	// Without any kubeconfig, the deferred loading config falls back to
+	// the in-cluster config and the service account's namespace.
+	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(loadingRules, &f.overrides)
+	config, err := clientConfig.ClientConfig()
+	if err != nil {
+		return nil, "", err
+	}
+
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil {
+		return nil, "", err
+	}
+
+	return config, namespace, nil
+}
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+)
+
+func applyCommand(client *clientFlags) *cli.Command {
+	var manifest, validate *string
+
+	return &cli.Command{
+		Name:  "apply",
+		Short: "Create or update the objects of a manifest file or directory",
+		Flags: func(flags *flag.FlagSet) {
+			manifest = flags.String("f", "", "Manifest file or directory to apply")
+			validate = flags.String("validate", string(orchestrator.ValidationWarn), "Selector validation of services: off | warn | strict")
+		},
+		Run: func(args []string) error {
+			if *manifest == "" {
+				return cli.Usagef("-f must be specified to apply")
+			}
+
+			validationMode, err := orchestrator.ParseValidationMode(*validate)
+			if err != nil {
+				return cli.Usagef("%s", err.Error())
+			}
+
+			kubernetesClientSet, namespace, err := client.client()
+			if err != nil {
+				return err
+			}
+
+			manifestOrchestrator := orchestrator.NewManifestOrchestrator(kubernetesClientSet, namespace)
+			manifestOrchestrator.Validation = validationMode
+			return manifestOrchestrator.Apply(*manifest)
+		},
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func autoscalerCommand(client *clientFlags) *cli.Command {
+	autoscalers := func() (*orchestrator.AutoscalerOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		return orchestrator.NewAutoscalerOrchestrator(kubernetesClientSet, namespace), nil
+	}
+
+	var autoscaler *autoscalerFlags
+	registerAutoscaler := func(flags *flag.FlagSet) {
+		autoscaler = registerAutoscalerFlags(flags)
+	}
+
+	var list *listFlags
+
+	hpa := &cli.Command{
+		Name:    "hpa",
+		Aliases: []string{"autoscaler", "autoscalers"},
+		Short:   "Scale deployments with their load",
+	}
+
+	hpa.Add(
+		&cli.Command{
+			Name:  "create",
+			Short: "Create an autoscaler for a deployment",
+			Args:  "DEPLOYMENT",
+			Flags: registerAutoscaler,
+			Run: func(args []string) error {
+				spec, err := autoscaler.spec(args)
+				if err != nil {
+					return err
+				}
+
+				if spec.MaxReplicas < 1 {
+					return cli.Usagef("-max must be specified to create an autoscaler")
+				}
+
+				autoscalerOrchestrator, err := autoscalers()
+				if err != nil {
+					return err
+				}
+
+				return autoscalerOrchestrator.Create(spec)
+			},
+		},
+		&cli.Command{
+			Name:  "update",
+			Short: "Change the replica bounds or targets of the autoscaler of a deployment",
+			Args:  "DEPLOYMENT",
+			Flags: registerAutoscaler,
+			Run: func(args []string) error {
+				spec, err := autoscaler.spec(args)
+				if err != nil {
+					return err
+				}
+
+				autoscalerOrchestrator, err := autoscalers()
+				if err != nil {
+					return err
+				}
+
+				return autoscalerOrchestrator.Update(spec)
+			},
+		},
+		&cli.Command{
+			Name:  "list",
+			Short: "List autoscalers with their current and desired replicas",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				autoscalerOrchestrator, err := autoscalers()
+				if err != nil {
+					return err
+				}
+
+				autoscalerList, err := autoscalerOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.Autoscalers(autoscalerList, listOptions.AllNamespaces))
+			},
+		},
+		&cli.Command{
+			Name:  "delete",
+			Short: "Delete an autoscaler, leaving the deployment at its current size",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				name, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				autoscalerOrchestrator, err := autoscalers()
+				if err != nil {
+					return err
+				}
+
+				return autoscalerOrchestrator.Delete(name)
+			},
+		},
+	)
+
+	return hpa
+}
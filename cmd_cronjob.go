@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func cronJobCommand(client *clientFlags) *cli.Command {
+	cronJobs := func() (*orchestrator.CronJobOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		return orchestrator.NewCronJobOrchestrator(kubernetesClientSet, namespace), nil
+	}
+
+	// byName builds the commands acting on an existing cron job.
+	byName := func(name, short string, act func(cronJobOrchestrator *orchestrator.CronJobOrchestrator, cronJobName string) error) *cli.Command {
+		return &cli.Command{
+			Name:  name,
+			Short: short,
+			Args:  "NAME",
+			Run: func(args []string) error {
+				cronJobName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				cronJobOrchestrator, err := cronJobs()
+				if err != nil {
+					return err
+				}
+
+				return act(cronJobOrchestrator, cronJobName)
+			},
+		}
+	}
+
+	var cronJob *cronJobFlags
+	var list *listFlags
+
+	cronJobGroup := &cli.Command{
+		Name:    "cronjob",
+		Aliases: []string{"cronjobs", "cj"},
+		Short:   "Schedule and manage cron jobs",
+	}
+
+	cronJobGroup.Add(
+		&cli.Command{
+			Name:  "create",
+			Short: "Schedule a job",
+			Long: "Schedule the job described by the flags, as job run does, with -schedule.\n" +
+				"The arguments, after --, become the container command.",
+			Args: "[-- COMMAND...]",
+			Flags: func(flags *flag.FlagSet) {
+				cronJob = registerCronJobFlags(flags)
+			},
+			Run: func(args []string) error {
+				cronJobSpec, err := cronJob.spec(args)
+				if err != nil {
+					return err
+				}
+
+				cronJobOrchestrator, err := cronJobs()
+				if err != nil {
+					return err
+				}
+
+				return cronJobOrchestrator.Create(cronJobSpec)
+			},
+		},
+		&cli.Command{
+			Name:  "list",
+			Short: "List cron jobs",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				cronJobOrchestrator, err := cronJobs()
+				if err != nil {
+					return err
+				}
+
+				cronJobList, err := cronJobOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.CronJobs(cronJobList, listOptions.AllNamespaces))
+			},
+		},
+		byName("suspend", "Stop scheduling a cron job", func(cronJobOrchestrator *orchestrator.CronJobOrchestrator, cronJobName string) error {
+			return cronJobOrchestrator.Suspend(cronJobName)
+		}),
+		byName("resume", "Schedule a suspended cron job again", func(cronJobOrchestrator *orchestrator.CronJobOrchestrator, cronJobName string) error {
+			return cronJobOrchestrator.Resume(cronJobName)
+		}),
+		byName("trigger", "Run a cron job right away", func(cronJobOrchestrator *orchestrator.CronJobOrchestrator, cronJobName string) error {
+			_, err := cronJobOrchestrator.Trigger(cronJobName)
+			return err
+		}),
+		byName("delete", "Delete a cron job and its jobs", func(cronJobOrchestrator *orchestrator.CronJobOrchestrator, cronJobName string) error {
+			return cronJobOrchestrator.Delete(cronJobName)
+		}),
+	)
+
+	return cronJobGroup
+}
@@ -0,0 +1,215 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func deployCommand(client *clientFlags) *cli.Command {
+	var apiVersion *string
+	deploy := &cli.Command{
+		Name:    "deploy",
+		Aliases: []string{"deployment", "deployments"},
+		Short:   "Manage deployments",
+		PersistentFlags: func(flags *flag.FlagSet) {
+			apiVersion = flags.String("api-version", "", "Deployment API version: "+orchestrator.AppsV1beta2+" | "+orchestrator.AppsV1beta1+" (default: the newest the server supports)")
+		},
+	}
+
+	deployments := func() (*orchestrator.DeploymentOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		deploymentOrchestrator := orchestrator.NewDeploymentOrchestrator(kubernetesClientSet, namespace)
+		deploymentOrchestrator.APIVersion = *apiVersion
+		return deploymentOrchestrator, nil
+	}
+
+	var deployment *deploymentFlags
+	registerDeployment := func(flags *flag.FlagSet) {
+		deployment = registerDeploymentFlags(flags)
+	}
+
+	var dryRun *bool
+	var replicas *int
+	var revision *int64
+	var list *listFlags
+
+	deploy.Add(
+		&cli.Command{
+			Name:  "create",
+			Short: "Create a deployment",
+			Args:  "[NAME]",
+			Flags: func(flags *flag.FlagSet) {
+				registerDeployment(flags)
+				dryRun = registerDryRunFlag(flags)
+			},
+			Run: func(args []string) error {
+				spec, err := deployment.spec(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator.DryRun = *dryRun
+				return deploymentOrchestrator.Create(spec)
+			},
+		},
+		&cli.Command{
+			Name:  "update",
+			Short: "Update the fields of a deployment given by flags or config",
+			Args:  "[NAME]",
+			Flags: registerDeployment,
+			Run: func(args []string) error {
+				spec, err := deployment.spec(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				return deploymentOrchestrator.Update(spec)
+			},
+		},
+		&cli.Command{
+			Name:  "scale",
+			Short: "Change the number of replicas of a deployment",
+			Args:  "NAME",
+			Flags: func(flags *flag.FlagSet) {
+				replicas = flags.Int("replicas", -1, "Number of replicas")
+			},
+			Run: func(args []string) error {
+				deployName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				if *replicas < 0 {
+					return cli.Usagef("-replicas must be specified to scale")
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				return deploymentOrchestrator.Scale(deployName, int32(*replicas))
+			},
+		},
+		&cli.Command{
+			Name:  "status",
+			Short: "Wait for the rollout of a deployment to finish",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				deployName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				return deploymentOrchestrator.RolloutStatus(deployName)
+			},
+		},
+		&cli.Command{
+			Name:  "history",
+			Short: "List the revisions of a deployment",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				deployName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				_, err = deploymentOrchestrator.History(deployName)
+				return err
+			},
+		},
+		&cli.Command{
+			Name:  "rollback",
+			Short: "Roll a deployment back to a previous revision",
+			Args:  "NAME",
+			Flags: func(flags *flag.FlagSet) {
+				revision = flags.Int64("revision", 0, "Revision to roll back to (0 means the previous one)")
+			},
+			Run: func(args []string) error {
+				deployName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				return deploymentOrchestrator.Rollback(deployName, *revision)
+			},
+		},
+		&cli.Command{
+			Name:  "list",
+			Short: "List deployments",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				deploymentList, err := deploymentOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.Deployments(deploymentList, listOptions.AllNamespaces))
+			},
+		},
+		&cli.Command{
+			Name:  "delete",
+			Short: "Delete a deployment",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				deployName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				deploymentOrchestrator, err := deployments()
+				if err != nil {
+					return err
+				}
+
+				return deploymentOrchestrator.Delete(deployName)
+			},
+		},
+	)
+
+	return deploy
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"flag"
+	"os"
+	"time"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func jobCommand(client *clientFlags) *cli.Command {
+	jobs := func() (*orchestrator.JobOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		return orchestrator.NewJobOrchestrator(kubernetesClientSet, namespace), nil
+	}
+
+	var job *jobFlags
+	var logs *logFlags
+	var timeout *time.Duration
+	var cleanup *string
+	var onInterrupt *string
+	var list *listFlags
+	var olderThan *time.Duration
+	var keepLast *int
+	var dryRun *bool
+	var outputFormat *string
+
+	jobGroup := &cli.Command{
+		Name:    "job",
+		Aliases: []string{"jobs"},
+		Short:   "Run and manage jobs",
+	}
+
+	jobGroup.Add(
+		&cli.Command{
+			Name:  "run",
+			Short: "Run a job and wait for it to finish",
+			Long: "Run a job and wait for it to finish, then print its output and result.\n" +
+				"Every run creates a new job, named after -name with a unique prefix.\n" +
+				"The arguments, after --, become the container command.",
+			Args: "[-- COMMAND...]",
+			Flags: func(flags *flag.FlagSet) {
+				job = registerJobFlags(flags, "Job base name (default job-example)")
+				logs = registerLogFlags(flags)
+				timeout = flags.Duration("timeout", 0, "How long to wait for the job to finish (0 means one hour)")
+				cleanup = flags.String("cleanup", string(orchestrator.CleanupNever), "Delete the job once it finished: never | on-success | always")
+				onInterrupt = flags.String("on-interrupt", onInterruptAsk, "What to do with the running job on Ctrl-C: ask | delete | detach (ask detaches on SIGTERM or without a terminal)")
+				dryRun = registerDryRunFlag(flags)
+			},
+			Run: func(args []string) error {
+				jobSpec, err := job.spec(args)
+				if err != nil {
+					return err
+				}
+
+				cleanupPolicy, err := orchestrator.ParseCleanupPolicy(*cleanup)
+				if err != nil {
+					return cli.Usagef("%s", err.Error())
+				}
+
+				switch *onInterrupt {
+				case onInterruptAsk, onInterruptDelete, onInterruptDetach:
+				default:
+					return cli.Usagef("invalid -on-interrupt, must be: ask | delete | detach")
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator.Logs = logs.options()
+				jobOrchestrator.Timeout = *timeout
+				jobOrchestrator.Cleanup = cleanupPolicy
+				interrupt := interruptOnSignal()
+				jobOrchestrator.Interrupt = interrupt.done
+				jobOrchestrator.DryRun = *dryRun
+
+				result, err := jobOrchestrator.Run(jobSpec)
+				if orchestrator.IsInterrupted(err) {
+					if handleErr := handleInterruptedJob(jobOrchestrator, result.Name, *onInterrupt, interrupt); handleErr != nil {
+						return handleErr
+					}
+				}
+
+				return err
+			},
+		},
+		&cli.Command{
+			Name:  "list",
+			Short: "List jobs",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				jobList, err := jobOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.Jobs(jobList, listOptions.AllNamespaces))
+			},
+		},
+		&cli.Command{
+			Name:  "logs",
+			Short: "Print the logs of a job",
+			Args:  "NAME",
+			Flags: func(flags *flag.FlagSet) {
+				logs = registerLogFlags(flags)
+			},
+			Run: func(args []string) error {
+				jobName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator.Logs = logs.options()
+				jobOrchestrator.Interrupt = interruptOnSignal().done
+				return jobOrchestrator.PrintLogs(jobName)
+			},
+		},
+		&cli.Command{
+			Name:  "status",
+			Short: "Print the result of a job",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				jobName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				_, err = jobOrchestrator.Status(jobName)
+				return err
+			},
+		},
+		&cli.Command{
+			Name:  "delete",
+			Short: "Delete a job and its pods",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				jobName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				return jobOrchestrator.Delete(jobName)
+			},
+		},
+		&cli.Command{
+			Name:  "prune",
+			Short: "Delete old finished jobs",
+			Flags: func(flags *flag.FlagSet) {
+				olderThan = flags.Duration("older-than", 0, "Delete jobs that finished longer ago than this, e.g. 24h")
+				keepLast = flags.Int("keep-last", 0, "Keep only this many of the most recently finished jobs")
+				dryRun = flags.Bool("dry-run", false, "Only list the jobs that would be deleted")
+				outputFormat = registerOutputFlag(flags)
+			},
+			Run: func(args []string) error {
+				if err := output.ValidateFormat(*outputFormat); err != nil {
+					return cli.Usagef("%s", err.Error())
+				}
+
+				jobOrchestrator, err := jobs()
+				if err != nil {
+					return err
+				}
+
+				pruned, err := jobOrchestrator.Prune(orchestrator.PruneOptions{
+					OlderThan: *olderThan,
+					KeepLast:  *keepLast,
+					DryRun:    *dryRun,
+				})
+				if err != nil || !*dryRun {
+					return err
+				}
+
+				return output.Print(os.Stdout, *outputFormat, output.Jobs(pruned, false))
+			},
+		},
+	)
+
+	return jobGroup
+}
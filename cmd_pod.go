@@ -0,0 +1,110 @@
+package main
+
+import (
+	"flag"
+	"os"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func podCommand(client *clientFlags) *cli.Command {
+	pods := func() (*orchestrator.PodOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		return orchestrator.NewPodOrchestrator(kubernetesClientSet, namespace), nil
+	}
+
+	var list *listFlags
+	var logs *logFlags
+	var container *string
+	var previous *bool
+
+	pod := &cli.Command{
+		Name:    "pod",
+		Aliases: []string{"pods", "po"},
+		Short:   "Inspect pods",
+	}
+
+	pod.Add(
+		&cli.Command{
+			Name:  "list",
+			Short: "List pods",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				podOrchestrator, err := pods()
+				if err != nil {
+					return err
+				}
+
+				podList, err := podOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.Pods(podList, listOptions.AllNamespaces))
+			},
+		},
+		&cli.Command{
+			Name:  "describe",
+			Short: "Show a pod with its container states and events",
+			Args:  "NAME",
+			Run: func(args []string) error {
+				podName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				podOrchestrator, err := pods()
+				if err != nil {
+					return err
+				}
+
+				described, events, err := podOrchestrator.Describe(podName)
+				if err != nil {
+					return err
+				}
+
+				return output.DescribePod(os.Stdout, described, events)
+			},
+		},
+		&cli.Command{
+			Name:  "logs",
+			Short: "Print the logs of a pod",
+			Args:  "NAME",
+			Flags: func(flags *flag.FlagSet) {
+				logs = registerLogFlags(flags)
+				container = flags.String("container", "", "Container to show the logs of (default: the only one)")
+				previous = flags.Bool("previous", false, "Show the logs of the previous run of a restarted container")
+			},
+			Run: func(args []string) error {
+				podName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				podOrchestrator, err := pods()
+				if err != nil {
+					return err
+				}
+
+				logOptions := logs.options()
+				logOptions.Previous = *previous
+				return podOrchestrator.PrintLogs(podName, *container, logOptions)
+			},
+		},
+	)
+
+	return pod
+}
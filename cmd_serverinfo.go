@@ -0,0 +1,42 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+)
+
+func serverInfoCommand(client *clientFlags) *cli.Command {
+	return &cli.Command{
+		Name:  "server-info",
+		Short: "Print the server version and the API versions it serves",
+		Run: func(args []string) error {
+			kubernetesClientSet, _, err := client.client()
+			if err != nil {
+				return err
+			}
+
+			serverInfo, err := orchestrator.GetServerInfo(kubernetesClientSet)
+			if err != nil {
+				return err
+			}
+
+			printServerInfo(serverInfo)
+			return nil
+		},
+	}
+}
+
+func printServerInfo(serverInfo *orchestrator.ServerInfo) {
+	fmt.Printf("Server version: %s (%s, %s)\n", serverInfo.Version.GitVersion, serverInfo.Version.GoVersion, serverInfo.Version.Platform)
+	if serverInfo.DeploymentVersion != "" {
+		fmt.Printf("Deployment API version: %s\n", serverInfo.DeploymentVersion)
+	} else {
+		fmt.Println("Deployment API version: none supported")
+	}
+	fmt.Println("API group versions:")
+	for _, groupVersion := range serverInfo.GroupVersions {
+		fmt.Println("  " + groupVersion)
+	}
+}
@@ -0,0 +1,117 @@
+package main
+
+import (
+	"flag"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+func serviceCommand(client *clientFlags) *cli.Command {
+	services := func() (*orchestrator.ServiceOrchestrator, error) {
+		kubernetesClientSet, namespace, err := client.client()
+		if err != nil {
+			return nil, err
+		}
+
+		return orchestrator.NewServiceOrchestrator(kubernetesClientSet, namespace), nil
+	}
+
+	var appName *string
+	var appPort *int
+	var validate *string
+	var dryRun *bool
+	var list *listFlags
+
+	service := &cli.Command{
+		Name:    "svc",
+		Aliases: []string{"service", "services"},
+		Short:   "Manage services",
+	}
+
+	service.Add(
+		&cli.Command{
+			Name:  "create",
+			Short: "Create a service exposing the pods of an app",
+			Args:  "[NAME]",
+			Flags: func(flags *flag.FlagSet) {
+				appName = flags.String("app", defaultAppName, "Application whose pods the service selects")
+				appPort = flags.Int("port", defaultAppPort, "Application port")
+				validate = flags.String("validate", string(orchestrator.ValidationWarn), "Selector validation: off | warn | strict")
+				dryRun = registerDryRunFlag(flags)
+			},
+			Run: func(args []string) error {
+				serviceName := defaultServiceName
+				if len(args) > 1 {
+					return cli.Usagef("at most one NAME argument is allowed")
+				} else if len(args) == 1 {
+					serviceName = args[0]
+				}
+
+				validationMode, err := orchestrator.ParseValidationMode(*validate)
+				if err != nil {
+					return cli.Usagef("%s", err.Error())
+				}
+
+				serviceOrchestrator, err := services()
+				if err != nil {
+					return err
+				}
+
+				serviceOrchestrator.Validation = validationMode
+				serviceOrchestrator.DryRun = *dryRun
+				return serviceOrchestrator.Create(serviceName, *appName, *appPort)
+			},
+		},
+		&cli.Command{
+			Name:  "list",
+			Short: "List services",
+			Flags: func(flags *flag.FlagSet) {
+				list = registerListFlags(flags)
+			},
+			Run: func(args []string) error {
+				listOptions, err := list.options()
+				if err != nil {
+					return err
+				}
+
+				serviceOrchestrator, err := services()
+				if err != nil {
+					return err
+				}
+
+				serviceList, err := serviceOrchestrator.List(listOptions)
+				if err != nil {
+					return err
+				}
+
+				return list.print(output.Services(serviceList, listOptions.AllNamespaces))
+			},
+		},
+		&cli.Command{
+			Name:  "delete",
+			Short: "Delete a service",
+			Args:  "NAME",
+			Flags: func(flags *flag.FlagSet) {
+				dryRun = registerDryRunFlag(flags)
+			},
+			Run: func(args []string) error {
+				serviceName, err := nameArg(args)
+				if err != nil {
+					return err
+				}
+
+				serviceOrchestrator, err := services()
+				if err != nil {
+					return err
+				}
+
+				serviceOrchestrator.DryRun = *dryRun
+				return serviceOrchestrator.Delete(serviceName)
+			},
+		},
+	)
+
+	return service
+}
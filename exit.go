@@ -0,0 +1,47 @@
+package main
+
+import (
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+)
+
+// Exit codes, one per class of orchestrator error.
+const (
+	exitError         = 1
+	exitUsage         = 2
+	exitNotFound      = 3
+	exitAlreadyExists = 4
+	exitConflict      = 5
+	exitForbidden     = 6
+	exitTimeout       = 7
+	exitInvalid       = 8
+	exitFailed        = 9
+	exitInterrupted   = 130
+)
+
+func exitCode(err error) int {
+	if _, ok := err.(*cli.UsageError); ok {
+		return exitUsage
+	}
+
+	switch orchestrator.ReasonForError(err) {
+	case orchestrator.ReasonNotFound:
+		return exitNotFound
+	case orchestrator.ReasonAlreadyExists:
+		return exitAlreadyExists
+	case orchestrator.ReasonConflict:
+		return exitConflict
+	case orchestrator.ReasonForbidden:
+		return exitForbidden
+	case orchestrator.ReasonTimeout:
+		return exitTimeout
+	case orchestrator.ReasonInvalid:
+		return exitInvalid
+	case orchestrator.ReasonFailed:
+		return exitFailed
+	case orchestrator.ReasonInterrupted:
+		return exitInterrupted
+	default:
+		return exitError
+	}
+}
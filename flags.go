@@ -0,0 +1,432 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/michelaquino/golang_kubernetes_example/cli"
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+	"github.com/michelaquino/golang_kubernetes_example/output"
+)
+
+// keyValueFlag collects repeated KEY=VALUE flags, such as -label or -env.
+// A single flag may also hold several comma separated pairs.
+type keyValueFlag map[string]string
+
+func (f keyValueFlag) String() string {
+	pairs := []string{}
+	for key, value := range f {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func (f keyValueFlag) Set(value string) error {
+	for _, pair := range strings.Split(value, ",") {
+		parts := strings.SplitN(pair, "=", 2)
+		if len(parts) != 2 || parts[0] == "" {
+			return fmt.Errorf("%q is not in KEY=VALUE form", pair)
+		}
+
+		f[parts[0]] = parts[1]
+	}
+
+	return nil
+}
+
+// givenFlags returns the names of the flags given on the command line.
+func givenFlags(flags *flag.FlagSet) map[string]bool {
+	set := map[string]bool{}
+	flags.Visit(func(given *flag.Flag) {
+		set[given.Name] = true
+	})
+
+	return set
+}
+
+// nameArg returns the single NAME argument of commands acting on an
+// existing object.
+func nameArg(args []string) (string, error) {
+	if len(args) != 1 {
+		return "", cli.Usagef("exactly one NAME argument is required")
+	}
+
+	return args[0], nil
+}
+
+// deploymentFlags are the flags describing a deployment.
+type deploymentFlags struct {
+	flags    *flag.FlagSet
+	config   *string
+	appName  *string
+	image    *string
+	port     *int
+	replicas *int
+	labels   keyValueFlag
+	env      keyValueFlag
+	requests keyValueFlag
+	limits   keyValueFlag
+}
+
+func registerDeploymentFlags(flags *flag.FlagSet) *deploymentFlags {
+	f := &deploymentFlags{
+		flags:    flags,
+		config:   flags.String("config", "", "YAML file describing the deployment, overridden by the other flags"),
+		appName:  flags.String("app", defaultAppName, "Application (container) name"),
+		image:    flags.String("image", "", "Container image (default nginx:1.13 on create)"),
+		port:     flags.Int("port", defaultAppPort, "Application port"),
+		replicas: flags.Int("replicas", -1, "Number of replicas (default 1 on create)"),
+		labels:   keyValueFlag{},
+		env:      keyValueFlag{},
+		requests: keyValueFlag{},
+		limits:   keyValueFlag{},
+	}
+
+	flags.Var(f.labels, "label", "Pod label as KEY=VALUE, can be repeated (default app=<app name>)")
+	flags.Var(f.env, "env", "Container environment variable as KEY=VALUE, can be repeated")
+	flags.Var(f.requests, "requests", "Container resource requests as NAME=QUANTITY, e.g. cpu=100m,memory=64Mi")
+	flags.Var(f.limits, "limits", "Container resource limits as NAME=QUANTITY, e.g. memory=128Mi")
+	return f
+}
+
+// spec builds the deployment spec from the config file, if any, the flags
+// given on the command line and the optional NAME argument, which take
+// precedence. Fields none of them sets are left empty, so Update leaves
+// them untouched.
+func (f *deploymentFlags) spec(args []string) (orchestrator.DeploymentSpec, error) {
+	spec := orchestrator.DeploymentSpec{}
+	if len(args) > 1 {
+		return spec, cli.Usagef("at most one NAME argument is allowed")
+	}
+
+	if *f.config != "" {
+		var err error
+		if spec, err = orchestrator.LoadDeploymentSpec(*f.config); err != nil {
+			return spec, err
+		}
+	}
+
+	set := givenFlags(f.flags)
+
+	if len(args) == 1 {
+		spec.Name = args[0]
+	} else if spec.Name == "" {
+		spec.Name = defaultDeployName
+	}
+
+	if set["app"] || spec.AppName == "" {
+		spec.AppName = *f.appName
+	}
+
+	if set["image"] {
+		spec.Image = *f.image
+	}
+
+	if set["port"] {
+		spec.Port = *f.port
+	}
+
+	if set["replicas"] {
+		replicas := int32(*f.replicas)
+		spec.Replicas = &replicas
+	}
+
+	if set["label"] {
+		spec.Labels = f.labels
+	}
+
+	if set["env"] {
+		spec.Env = f.env
+	}
+
+	if set["requests"] {
+		spec.Resources.Requests = f.requests
+	}
+
+	if set["limits"] {
+		spec.Resources.Limits = f.limits
+	}
+
+	return spec, nil
+}
+
+// jobFlags are the flags describing a job. The positional arguments, if
+// any, become the container command.
+type jobFlags struct {
+	flags                 *flag.FlagSet
+	config                *string
+	name                  *string
+	image                 *string
+	env                   keyValueFlag
+	requests              keyValueFlag
+	limits                keyValueFlag
+	parallelism           *int
+	completions           *int
+	backoffLimit          *int
+	activeDeadlineSeconds *int64
+}
+
+// registerJobFlags registers the job flags, -name being described by
+// nameUsage.
+func registerJobFlags(flags *flag.FlagSet, nameUsage string) *jobFlags {
+	f := &jobFlags{
+		flags:                 flags,
+		config:                flags.String("config", "", "YAML file describing the job, overridden by the other flags"),
+		name:                  flags.String("name", "", nameUsage),
+		image:                 flags.String("image", "", "Container image (default ubuntu:latest)"),
+		env:                   keyValueFlag{},
+		requests:              keyValueFlag{},
+		limits:                keyValueFlag{},
+		parallelism:           flags.Int("parallelism", -1, "Maximum number of pods running in parallel"),
+		completions:           flags.Int("completions", -1, "Number of successful pods needed to complete the job"),
+		backoffLimit:          flags.Int("backoff-limit", -1, "Number of retries before the job is marked failed"),
+		activeDeadlineSeconds: flags.Int64("active-deadline", -1, "Seconds the job may run before it is terminated"),
+	}
+
+	flags.Var(f.env, "env", "Environment variable as KEY=VALUE, can be repeated")
+	flags.Var(f.requests, "requests", "Resource requests as NAME=QUANTITY, e.g. cpu=100m,memory=64Mi")
+	flags.Var(f.limits, "limits", "Resource limits as NAME=QUANTITY, e.g. memory=128Mi")
+	return f
+}
+
+// spec builds the job spec from the config file, if any, the flags given
+// on the command line and the command arguments, which take precedence.
+func (f *jobFlags) spec(command []string) (orchestrator.JobSpec, error) {
+	spec := orchestrator.JobSpec{}
+	if *f.config != "" {
+		var err error
+		if spec, err = orchestrator.LoadJobSpec(*f.config); err != nil {
+			return spec, err
+		}
+	}
+
+	f.override(&spec, command)
+	return spec, nil
+}
+
+// override sets the fields of the spec given on the command line.
+func (f *jobFlags) override(spec *orchestrator.JobSpec, command []string) {
+	set := givenFlags(f.flags)
+
+	if set["name"] {
+		spec.Name = *f.name
+	}
+
+	if set["image"] {
+		spec.Image = *f.image
+	}
+
+	if len(command) > 0 {
+		spec.Command = command
+	}
+
+	if set["env"] {
+		spec.Env = f.env
+	}
+
+	if set["requests"] {
+		spec.Resources.Requests = f.requests
+	}
+
+	if set["limits"] {
+		spec.Resources.Limits = f.limits
+	}
+
+	if set["parallelism"] {
+		parallelism := int32(*f.parallelism)
+		spec.Parallelism = &parallelism
+	}
+
+	if set["completions"] {
+		completions := int32(*f.completions)
+		spec.Completions = &completions
+	}
+
+	if set["backoff-limit"] {
+		backoffLimit := int32(*f.backoffLimit)
+		spec.BackoffLimit = &backoffLimit
+	}
+
+	if set["active-deadline"] {
+		spec.ActiveDeadlineSeconds = f.activeDeadlineSeconds
+	}
+}
+
+// cronJobFlags are the job flags plus the flags scheduling the job. With
+// them, -config is read as a cron job spec.
+type cronJobFlags struct {
+	job               *jobFlags
+	schedule          *string
+	concurrencyPolicy *string
+}
+
+func registerCronJobFlags(flags *flag.FlagSet) *cronJobFlags {
+	return &cronJobFlags{
+		job:               registerJobFlags(flags, "Cron job name, required"),
+		schedule:          flags.String("schedule", "", "Schedule in cron format, e.g. \"0 2 * * *\""),
+		concurrencyPolicy: flags.String("concurrency-policy", "", "Concurrency policy: Allow | Forbid | Replace"),
+	}
+}
+
+// spec builds the cron job spec from the config file, if any, the flags
+// given on the command line and the command arguments, which take
+// precedence.
+func (f *cronJobFlags) spec(command []string) (orchestrator.CronJobSpec, error) {
+	spec := orchestrator.CronJobSpec{}
+	if *f.job.config != "" {
+		var err error
+		if spec, err = orchestrator.LoadCronJobSpec(*f.job.config); err != nil {
+			return spec, err
+		}
+	}
+
+	f.job.override(&spec.JobSpec, command)
+	if spec.Name == "" {
+		return spec, cli.Usagef("-name, or name in the -config file, is required")
+	}
+
+	if *f.schedule != "" {
+		spec.Schedule = *f.schedule
+	}
+
+	if *f.concurrencyPolicy != "" {
+		spec.ConcurrencyPolicy = *f.concurrencyPolicy
+	}
+
+	return spec, nil
+}
+
+// autoscalerFlags are the flags describing an autoscaler. The DEPLOYMENT
+// argument names the scaled deployment.
+type autoscalerFlags struct {
+	flags       *flag.FlagSet
+	minReplicas *int
+	maxReplicas *int
+	cpuPercent  *int
+	metrics     keyValueFlag
+}
+
+func registerAutoscalerFlags(flags *flag.FlagSet) *autoscalerFlags {
+	f := &autoscalerFlags{
+		flags:       flags,
+		minReplicas: flags.Int("min", 1, "Lowest number of replicas"),
+		maxReplicas: flags.Int("max", 0, "Highest number of replicas"),
+		cpuPercent:  flags.Int("cpu-percent", 0, "Average CPU usage to aim at, as a percentage of the CPU requests (default 80 without -metric)"),
+		metrics:     keyValueFlag{},
+	}
+
+	flags.Var(f.metrics, "metric", "Per-pod custom metric to aim at as NAME=AVERAGE, e.g. http_requests=10, can be repeated (needs autoscaling/v2alpha1)")
+	return f
+}
+
+// spec builds the autoscaler spec from the DEPLOYMENT argument and the
+// flags given on the command line. Fields not given are left empty, so
+// Update leaves them untouched.
+func (f *autoscalerFlags) spec(args []string) (orchestrator.AutoscalerSpec, error) {
+	spec := orchestrator.AutoscalerSpec{}
+	if len(args) != 1 {
+		return spec, cli.Usagef("exactly one DEPLOYMENT argument is required")
+	}
+	spec.Deployment = args[0]
+
+	set := givenFlags(f.flags)
+
+	if set["min"] {
+		minReplicas := int32(*f.minReplicas)
+		spec.MinReplicas = &minReplicas
+	}
+
+	if set["max"] {
+		spec.MaxReplicas = int32(*f.maxReplicas)
+	}
+
+	if set["cpu-percent"] {
+		cpuPercent := int32(*f.cpuPercent)
+		spec.CPUTargetPercentage = &cpuPercent
+	}
+
+	if set["metric"] {
+		spec.CustomMetrics = f.metrics
+	}
+
+	return spec, nil
+}
+
+// logFlags select the log lines shown by the logs commands.
+type logFlags struct {
+	follow     *bool
+	since      *time.Duration
+	tail       *int64
+	timestamps *bool
+}
+
+func registerLogFlags(flags *flag.FlagSet) *logFlags {
+	return &logFlags{
+		follow:     flags.Bool("follow", false, "Stream the logs as they are written"),
+		since:      flags.Duration("since", 0, "Only show logs newer than this duration, e.g. 5m"),
+		tail:       flags.Int64("tail", 0, "Only show this many of the most recent log lines (0 shows all)"),
+		timestamps: flags.Bool("timestamps", false, "Prefix log lines with their timestamp"),
+	}
+}
+
+func (f *logFlags) options() orchestrator.LogOptions {
+	return orchestrator.LogOptions{
+		Follow:     *f.follow,
+		Since:      *f.since,
+		Tail:       *f.tail,
+		Timestamps: *f.timestamps,
+	}
+}
+
+// listFlags select the objects of the list commands and how to print them.
+type listFlags struct {
+	allNamespaces *bool
+	selector      *string
+	fieldSelector *string
+	pageSize      *int64
+	output        *string
+}
+
+func registerListFlags(flags *flag.FlagSet) *listFlags {
+	return &listFlags{
+		allNamespaces: flags.Bool("all-namespaces", false, "List objects across all namespaces"),
+		selector:      flags.String("selector", "", "Label selector to filter by, e.g. app=web,tier!=cache"),
+		fieldSelector: flags.String("field-selector", "", "Field selector to filter by, e.g. status.phase=Running"),
+		pageSize:      flags.Int64("page-size", 500, "Fetch the list in pages of this many objects (0 fetches it all at once)"),
+		output:        registerOutputFlag(flags),
+	}
+}
+
+// options checks the output format and returns the list options. It runs
+// before listing, so a typo does not cost a round trip.
+func (f *listFlags) options() (orchestrator.ListOptions, error) {
+	if err := output.ValidateFormat(*f.output); err != nil {
+		return orchestrator.ListOptions{}, cli.Usagef("%s", err.Error())
+	}
+
+	return orchestrator.ListOptions{
+		AllNamespaces: *f.allNamespaces,
+		LabelSelector: *f.selector,
+		FieldSelector: *f.fieldSelector,
+		Limit:         *f.pageSize,
+	}, nil
+}
+
+func (f *listFlags) print(list *output.List) error {
+	return output.Print(os.Stdout, *f.output, list)
+}
+
+// registerDryRunFlag registers -dry-run on the commands changing objects.
+func registerDryRunFlag(flags *flag.FlagSet) *bool {
+	return flags.Bool("dry-run", false, "Only print the object that would be sent and how it differs from the live one")
+}
+
+func registerOutputFlag(flags *flag.FlagSet) *string {
+	return flags.String("output", "table", "Output format: "+output.Formats)
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"golang.org/x/crypto/ssh/terminal"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator"
+)
+
+// What to do with a job left running when job run is interrupted.
+const (
+	onInterruptAsk    = "ask"
+	onInterruptDelete = "delete"
+	onInterruptDetach = "detach"
+)
+
+// interruption is the first SIGINT or SIGTERM the program receives.
+type interruption struct {
+	// done is closed once the signal is received.
+	done chan struct{}
+
+	// signal is the signal received, set before done is closed.
+	signal os.Signal
+}
+
+// interruptOnSignal starts waiting for the first SIGINT or SIGTERM.
+// Further signals get their default behaviour back, so a second Ctrl-C
+// kills the program.
+func interruptOnSignal() *interruption {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+
+	interrupt := &interruption{done: make(chan struct{})}
+	go func() {
+		interrupt.signal = <-signals
+		signal.Stop(signals)
+		close(interrupt.done)
+	}()
+
+	return interrupt
+}
+
+// handleInterruptedJob deletes the job with its pods, or leaves it running
+// and tells how to check on it later, as -on-interrupt or the user says.
+// The user is only asked after a Ctrl-C, with a terminal to answer on; the
+// job is left running otherwise, as when a SIGTERM stops the program.
+func handleInterruptedJob(jobOrchestrator *orchestrator.JobOrchestrator, jobName, onInterrupt string, interrupt *interruption) error {
+	if onInterrupt == onInterruptAsk && (interrupt.signal != os.Interrupt || !terminal.IsTerminal(int(os.Stdin.Fd()))) {
+		onInterrupt = onInterruptDetach
+	}
+
+	if onInterrupt == onInterruptAsk {
+		fmt.Printf("\nDelete job %s and its pods? [y/N] ", jobName)
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+
+		onInterrupt = onInterruptDetach
+		if strings.HasPrefix(strings.ToLower(strings.TrimSpace(answer)), "y") {
+			onInterrupt = onInterruptDelete
+		}
+	}
+
+	if onInterrupt == onInterruptDelete {
+		return jobOrchestrator.Delete(jobName)
+	}
+
+	fmt.Printf("Job %s keeps running. Check on it with:\n", jobName)
+	fmt.Printf("  %s job status %s\n", programName, jobName)
+	fmt.Printf("  %s job logs -follow %s\n", programName, jobName)
+	return nil
+}
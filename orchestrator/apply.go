@@ -0,0 +1,272 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	batchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var manifestExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
+}
+
+type ManifestOrchestrator struct {
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+
+	// Validation sets whether Apply checks that the services it applies
+	// select running workloads, as ServiceOrchestrator.Create does. It
+	// defaults to ValidationWarn.
+	Validation ValidationMode
+
+	// discovered caches the deployment API version the service checks
+	// list deployments with, shared by all the services applied.
+	discovered *discoveredVersion
+}
+
+func NewManifestOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *ManifestOrchestrator {
+	return &ManifestOrchestrator{
+		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
+		Validation:          ValidationWarn,
+		discovered:          &discoveredVersion{},
+	}
+}
+
+// Apply creates or updates every object described in the manifest file, or
+// in the .yaml, .yml and .json files of a directory. Files may hold several
+// YAML documents separated by "---". Objects without a namespace go to the
+// orchestrator's namespace. Services are checked as the Validation mode
+// says, against the objects applied before them.
+func (m ManifestOrchestrator) Apply(path string) error {
+	files, err := manifestFiles(path)
+	if err != nil {
+		return wrapError("read", "manifests", path, err)
+	}
+
+	for _, file := range files {
+		objects, err := decodeManifest(file)
+		if err != nil {
+			return wrapError("decode", "manifest", file, err)
+		}
+
+		for _, object := range objects {
+			if err := m.applyObject(file, object); err != nil {
+				return wrapError("apply", "manifest", file, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (m ManifestOrchestrator) applyObject(file string, object runtime.Object) error {
+	client, supported := m.manifestClient(object)
+	if !supported {
+		return newError("apply", "manifest", file, ReasonInvalid,
+			fmt.Errorf("unsupported kind %s", object.GetObjectKind().GroupVersionKind()))
+	}
+
+	if service, ok := object.(*apiv1.Service); ok {
+		services := ServiceOrchestrator{
+			KubernetesClientSet: m.KubernetesClientSet,
+			Namespace:           client.namespace,
+			Validation:          m.Validation,
+			discovered:          m.discovered,
+		}
+		if err := services.checkService(service); err != nil {
+			return err
+		}
+	}
+
+	meta := object.(metav1.Object)
+	return createOrUpdate(client.kind, meta.GetName(), client.get, client.create,
+		func(live metav1.Object) error {
+			meta.SetResourceVersion(live.GetResourceVersion())
+			keepServerFields(object, live)
+			return client.update()
+		},
+	)
+}
+
+// manifestClient holds the calls applying an object of a manifest in its
+// namespace.
+type manifestClient struct {
+	kind      string
+	namespace string
+	get       liveObject
+	create    func() error
+	update    func() error
+}
+
+// manifestClient returns the calls applying the object, or false for the
+// kinds Apply does not support. Objects without a namespace go to the
+// orchestrator's one.
+func (m ManifestOrchestrator) manifestClient(object runtime.Object) (manifestClient, bool) {
+	meta, ok := object.(metav1.Object)
+	if !ok {
+		return manifestClient{}, false
+	}
+
+	namespace := meta.GetNamespace()
+	if namespace == "" {
+		namespace = m.Namespace
+	}
+
+	clientSet := m.KubernetesClientSet
+	name := meta.GetName()
+	options := metav1.GetOptions{}
+
+	switch typed := object.(type) {
+	case *appsv1beta1.Deployment:
+		client := clientSet.AppsV1beta1().Deployments(namespace)
+		return manifestClient{"deployment", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *appsv1beta2.Deployment:
+		client := clientSet.AppsV1beta2().Deployments(namespace)
+		return manifestClient{"deployment", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *extensionsv1beta1.Deployment:
+		client := clientSet.ExtensionsV1beta1().Deployments(namespace)
+		return manifestClient{"deployment", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *apiv1.Service:
+		client := clientSet.CoreV1().Services(namespace)
+		return manifestClient{"service", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *apiv1.ConfigMap:
+		client := clientSet.CoreV1().ConfigMaps(namespace)
+		return manifestClient{"config map", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *apiv1.Secret:
+		client := clientSet.CoreV1().Secrets(namespace)
+		return manifestClient{"secret", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	case *batchv1.Job:
+		client := clientSet.BatchV1().Jobs(namespace)
+		return manifestClient{"job", namespace,
+			func() (metav1.Object, error) { return client.Get(name, options) },
+			func() error { _, err := client.Create(typed); return err },
+			func() error { _, err := client.Update(typed); return err },
+		}, true
+	}
+
+	return manifestClient{}, false
+}
+
+// keepServerFields copies into the object the fields of the live one that
+// the server set and will not let an update change or drop.
+func keepServerFields(object runtime.Object, live metav1.Object) {
+	switch typed := object.(type) {
+	case *apiv1.Service:
+		typed.Spec.ClusterIP = live.(*apiv1.Service).Spec.ClusterIP
+	case *batchv1.Job:
+		// The pod template of a job is immutable, so updates only succeed
+		// for fields such as parallelism or activeDeadlineSeconds.
+		liveJob := live.(*batchv1.Job)
+		typed.Spec.Selector = liveJob.Spec.Selector
+		typed.Spec.Template.Labels = liveJob.Spec.Template.Labels
+	}
+}
+
+// manifestFiles resolves a path to the manifest files it names.
+func manifestFiles(path string) ([]string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+
+	entries, err := ioutil.ReadDir(path)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	for _, entry := range entries {
+		if !entry.IsDir() && manifestExtensions[filepath.Ext(entry.Name())] {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+
+	return files, nil
+}
+
+// decodeManifest decodes every YAML or JSON document of a file into its
+// typed API object.
+func decodeManifest(file string) ([]runtime.Object, error) {
+	content, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer content.Close()
+
+	decoder := scheme.Codecs.UniversalDeserializer()
+	reader := yaml.NewYAMLReader(bufio.NewReader(content))
+
+	objects := []runtime.Object{}
+	for {
+		document, err := reader.Read()
+		if err == io.EOF {
+			return objects, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		documentJSON, err := yaml.ToJSON(document)
+		if err != nil {
+			return nil, err
+		}
+
+		// Skip documents that are empty or only hold comments.
+		if trimmed := bytes.TrimSpace(documentJSON); len(trimmed) == 0 || string(trimmed) == "null" {
+			continue
+		}
+
+		object, _, err := decoder.Decode(documentJSON, nil, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		objects = append(objects, object)
+	}
+}
@@ -0,0 +1,110 @@
+package orchestrator
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+const applyTestManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+data:
+  greeting: hello
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  selector:
+    app: web
+  ports:
+  - port: 80
+    targetPort: 8080
+`
+
+func TestManifestApply(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := filepath.Join(dir, "web.yaml")
+	if err := ioutil.WriteFile(manifest, []byte(applyTestManifest), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests := NewManifestOrchestrator(server.ClientSet(), "default")
+	manifests.Validation = ValidationOff
+
+	if err := manifests.Apply(manifest); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	if err := server.Modify("services", "default", "web", func(service orchestratortest.Object) {
+		service["spec"].(map[string]interface{})["clusterIP"] = "10.0.0.10"
+	}); err != nil {
+		t.Fatalf("Modify: %v", err)
+	}
+
+	// Applied again, the objects are updated and the service keeps the
+	// cluster IP the server allocated.
+	if err := manifests.Apply(dir); err != nil {
+		t.Fatalf("Apply again: %v", err)
+	}
+
+	var configMap apiv1.ConfigMap
+	if err := server.Get("configmaps", "default", "web-config", &configMap); err != nil {
+		t.Fatalf("Get config map: %v", err)
+	}
+
+	if configMap.Data["greeting"] != "hello" {
+		t.Errorf("got config map data %v, want greeting=hello", configMap.Data)
+	}
+
+	var service apiv1.Service
+	if err := server.Get("services", "default", "web", &service); err != nil {
+		t.Fatalf("Get service: %v", err)
+	}
+
+	if service.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("got cluster IP %q, want 10.0.0.10", service.Spec.ClusterIP)
+	}
+
+	manifests.Validation = ValidationStrict
+	if err := manifests.Apply(manifest); !IsInvalid(err) {
+		t.Errorf("Apply of a service selecting no pods: got %v, want an Invalid error", err)
+	}
+}
+
+func TestManifestApplyUnsupportedKind(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	dir, err := ioutil.TempDir("", "apply")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manifest := filepath.Join(dir, "account.yaml")
+	if err := ioutil.WriteFile(manifest, []byte("apiVersion: v1\nkind: ServiceAccount\nmetadata:\n  name: web\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	manifests := NewManifestOrchestrator(server.ClientSet(), "default")
+	if err := manifests.Apply(manifest); !IsInvalid(err) {
+		t.Errorf("Apply of a service account: got %v, want an Invalid error", err)
+	}
+}
@@ -0,0 +1,344 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	autoscalingv2alpha1 "k8s.io/api/autoscaling/v2alpha1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+// Kind of the autoscalers' scale target. Its API version is the
+// deployment API version the server prefers, as PreferredDeploymentVersion
+// finds it.
+const scaleTargetKind = "Deployment"
+
+// autoscalingV2alpha1 is the API version custom metrics need.
+const autoscalingV2alpha1 = "autoscaling/v2alpha1"
+
+// AutoscalerSpec describes a horizontal pod autoscaler keeping the replica
+// count of a deployment in line with its load.
+type AutoscalerSpec struct {
+	// Deployment is the name of the scaled deployment. The autoscaler is
+	// named after it.
+	Deployment string
+
+	// MinReplicas is the lowest replica count, 1 when nil.
+	MinReplicas *int32
+
+	// MaxReplicas is the highest replica count. Create requires it and
+	// Update leaves it alone when zero.
+	MaxReplicas int32
+
+	// CPUTargetPercentage is the average CPU usage to aim at, as a
+	// percentage of the pods' CPU requests. When neither it nor
+	// CustomMetrics is set, the server defaults it to 80.
+	CPUTargetPercentage *int32
+
+	// CustomMetrics maps per-pod custom metrics to the average value to
+	// aim at, as quantities such as "10" or "500m". They need the
+	// autoscaling/v2alpha1 API and a custom metrics server on the cluster.
+	CustomMetrics map[string]string
+}
+
+type AutoscalerOrchestrator struct {
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+}
+
+func NewAutoscalerOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *AutoscalerOrchestrator {
+	return &AutoscalerOrchestrator{
+		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
+	}
+}
+
+// Create creates an autoscaler for an existing deployment. It goes through
+// autoscaling/v1 unless the spec has custom metrics, which only
+// autoscaling/v2alpha1 knows about.
+func (a AutoscalerOrchestrator) Create(spec AutoscalerSpec) error {
+	if spec.MaxReplicas < 1 {
+		return newError("create", "autoscaler", spec.Deployment, ReasonInvalid, errors.New("the maximum number of replicas must be at least 1"))
+	}
+
+	if err := a.checkMetricsAPI("create", spec); err != nil {
+		return err
+	}
+
+	targetAPIVersion, err := a.checkTarget(spec)
+	if err != nil {
+		return wrapError("create", "autoscaler", spec.Deployment, err)
+	}
+
+	fmt.Println("Creating autoscaler...")
+	if len(spec.CustomMetrics) > 0 {
+		metrics, err := spec.metrics(nil)
+		if err != nil {
+			return newError("create", "autoscaler", spec.Deployment, ReasonInvalid, err)
+		}
+
+		autoscaler := &autoscalingv2alpha1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: spec.Deployment,
+			},
+			Spec: autoscalingv2alpha1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv2alpha1.CrossVersionObjectReference{
+					Kind:       scaleTargetKind,
+					Name:       spec.Deployment,
+					APIVersion: targetAPIVersion,
+				},
+				MinReplicas: spec.MinReplicas,
+				MaxReplicas: spec.MaxReplicas,
+				Metrics:     metrics,
+			},
+		}
+
+		if _, err := a.KubernetesClientSet.AutoscalingV2alpha1().HorizontalPodAutoscalers(a.Namespace).Create(autoscaler); err != nil {
+			return wrapError("create", "autoscaler", spec.Deployment, err)
+		}
+	} else {
+		autoscaler := &autoscalingv1.HorizontalPodAutoscaler{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: spec.Deployment,
+			},
+			Spec: autoscalingv1.HorizontalPodAutoscalerSpec{
+				ScaleTargetRef: autoscalingv1.CrossVersionObjectReference{
+					Kind:       scaleTargetKind,
+					Name:       spec.Deployment,
+					APIVersion: targetAPIVersion,
+				},
+				MinReplicas:                    spec.MinReplicas,
+				MaxReplicas:                    spec.MaxReplicas,
+				TargetCPUUtilizationPercentage: spec.CPUTargetPercentage,
+			},
+		}
+
+		if _, err := a.KubernetesClientSet.AutoscalingV1().HorizontalPodAutoscalers(a.Namespace).Create(autoscaler); err != nil {
+			return wrapError("create", "autoscaler", spec.Deployment, err)
+		}
+	}
+
+	fmt.Printf("Created autoscaler %q scaling deployment %q up to %d replicas.\n", spec.Deployment, spec.Deployment, spec.MaxReplicas)
+	return nil
+}
+
+// Update applies the set fields of the spec to the autoscaler of a
+// deployment. Custom metrics, when given, replace all the per-pod metrics
+// of the autoscaler.
+func (a AutoscalerOrchestrator) Update(spec AutoscalerSpec) error {
+	if err := a.checkMetricsAPI("update", spec); err != nil {
+		return err
+	}
+
+	fmt.Println("Updating autoscaler...")
+
+	var err error
+	if len(spec.CustomMetrics) > 0 {
+		err = a.updateV2alpha1(spec)
+	} else {
+		err = a.updateV1(spec)
+	}
+	if err != nil {
+		return wrapError("update", "autoscaler", spec.Deployment, err)
+	}
+
+	fmt.Printf("Updated autoscaler %q.\n", spec.Deployment)
+	return nil
+}
+
+// updateV1 updates the autoscaler through autoscaling/v1. The server keeps
+// the metrics v1 cannot express in an annotation, which the update sends
+// back untouched.
+func (a AutoscalerOrchestrator) updateV1(spec AutoscalerSpec) error {
+	autoscalersClient := a.KubernetesClientSet.AutoscalingV1().HorizontalPodAutoscalers(a.Namespace)
+
+	return retryOnConflict(func() error {
+		autoscaler, err := autoscalersClient.Get(spec.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if spec.MinReplicas != nil {
+			autoscaler.Spec.MinReplicas = spec.MinReplicas
+		}
+
+		if spec.MaxReplicas > 0 {
+			autoscaler.Spec.MaxReplicas = spec.MaxReplicas
+		}
+
+		if spec.CPUTargetPercentage != nil {
+			autoscaler.Spec.TargetCPUUtilizationPercentage = spec.CPUTargetPercentage
+		}
+
+		_, err = autoscalersClient.Update(autoscaler)
+		return err
+	})
+}
+
+func (a AutoscalerOrchestrator) updateV2alpha1(spec AutoscalerSpec) error {
+	autoscalersClient := a.KubernetesClientSet.AutoscalingV2alpha1().HorizontalPodAutoscalers(a.Namespace)
+
+	return retryOnConflict(func() error {
+		autoscaler, err := autoscalersClient.Get(spec.Deployment, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		if spec.MinReplicas != nil {
+			autoscaler.Spec.MinReplicas = spec.MinReplicas
+		}
+
+		if spec.MaxReplicas > 0 {
+			autoscaler.Spec.MaxReplicas = spec.MaxReplicas
+		}
+
+		metrics, err := spec.metrics(autoscaler.Spec.Metrics)
+		if err != nil {
+			return newError("update", "autoscaler", spec.Deployment, ReasonInvalid, err)
+		}
+		autoscaler.Spec.Metrics = metrics
+
+		_, err = autoscalersClient.Update(autoscaler)
+		return err
+	})
+}
+
+// metrics merges the CPU target and custom metrics of the spec into the
+// current metrics of an autoscaler: the CPU target replaces the CPU
+// metric, and the custom metrics replace the per-pod metrics.
+func (s AutoscalerSpec) metrics(current []autoscalingv2alpha1.MetricSpec) ([]autoscalingv2alpha1.MetricSpec, error) {
+	metrics := []autoscalingv2alpha1.MetricSpec{}
+	for _, metric := range current {
+		switch {
+		case metric.Type == autoscalingv2alpha1.PodsMetricSourceType && len(s.CustomMetrics) > 0:
+			// Replaced by the custom metrics below.
+		case metric.Type == autoscalingv2alpha1.ResourceMetricSourceType && metric.Resource.Name == apiv1.ResourceCPU && s.CPUTargetPercentage != nil:
+			// Replaced by the CPU target below.
+		default:
+			metrics = append(metrics, metric)
+		}
+	}
+
+	if s.CPUTargetPercentage != nil {
+		metrics = append(metrics, autoscalingv2alpha1.MetricSpec{
+			Type: autoscalingv2alpha1.ResourceMetricSourceType,
+			Resource: &autoscalingv2alpha1.ResourceMetricSource{
+				Name:                     apiv1.ResourceCPU,
+				TargetAverageUtilization: s.CPUTargetPercentage,
+			},
+		})
+	}
+
+	names := []string{}
+	for name := range s.CustomMetrics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		target, err := resource.ParseQuantity(s.CustomMetrics[name])
+		if err != nil {
+			return nil, fmt.Errorf("metric %s: %v", name, err)
+		}
+
+		metrics = append(metrics, autoscalingv2alpha1.MetricSpec{
+			Type: autoscalingv2alpha1.PodsMetricSourceType,
+			Pods: &autoscalingv2alpha1.PodsMetricSource{
+				MetricName:         name,
+				TargetAverageValue: target,
+			},
+		})
+	}
+
+	return metrics, nil
+}
+
+// checkMetricsAPI makes sure the server serves autoscaling/v2alpha1 when
+// the spec has custom metrics.
+func (a AutoscalerOrchestrator) checkMetricsAPI(op string, spec AutoscalerSpec) error {
+	if len(spec.CustomMetrics) == 0 {
+		return nil
+	}
+
+	served, err := servesGroupVersion(a.KubernetesClientSet, autoscalingV2alpha1)
+	if err != nil {
+		return wrapError(op, "autoscaler", spec.Deployment, err)
+	}
+
+	if !served {
+		return newError(op, "autoscaler", spec.Deployment, ReasonInvalid,
+			fmt.Errorf("custom metrics need the %s API, which the server does not serve; it must be enabled on the API server with --runtime-config=%s=true", autoscalingV2alpha1, autoscalingV2alpha1))
+	}
+
+	return nil
+}
+
+// checkTarget makes sure the deployment exists, warns about its
+// containers without a CPU request, whose CPU usage cannot be turned into
+// a percentage, and returns the API version to reach it with.
+func (a AutoscalerOrchestrator) checkTarget(spec AutoscalerSpec) (string, error) {
+	apiVersion, err := PreferredDeploymentVersion(a.KubernetesClientSet)
+	if err != nil {
+		return "", err
+	}
+
+	deploymentsClient, err := newDeploymentsClient(a.KubernetesClientSet, a.Namespace, apiVersion)
+	if err != nil {
+		return "", err
+	}
+
+	deployment, err := deploymentsClient.Get(spec.Deployment, metav1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
+
+	if len(spec.CustomMetrics) > 0 && spec.CPUTargetPercentage == nil {
+		return apiVersion, nil
+	}
+
+	for _, container := range deployment.Spec.Template.Spec.Containers {
+		if _, found := container.Resources.Requests[apiv1.ResourceCPU]; !found {
+			fmt.Printf("Warning: container %q of deployment %q has no CPU request, so the autoscaler cannot scale it on CPU usage.\n", container.Name, spec.Deployment)
+		}
+	}
+
+	return apiVersion, nil
+}
+
+// List returns the autoscalers selected by the options.
+func (a AutoscalerOrchestrator) List(options ListOptions) ([]autoscalingv1.HorizontalPodAutoscaler, error) {
+	autoscalersClient := a.KubernetesClientSet.AutoscalingV1().HorizontalPodAutoscalers(listNamespace(a.Namespace, options.AllNamespaces))
+
+	var autoscalers []autoscalingv1.HorizontalPodAutoscaler
+	err := listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := autoscalersClient.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		autoscalers = append(autoscalers, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, wrapError("list", "autoscalers", "", err)
+	}
+
+	return autoscalers, nil
+}
+
+// Delete deletes an autoscaler. The deployment keeps its current replica
+// count.
+func (a AutoscalerOrchestrator) Delete(name string) error {
+	err := a.KubernetesClientSet.AutoscalingV1().HorizontalPodAutoscalers(a.Namespace).Delete(name, &metav1.DeleteOptions{})
+	if err != nil {
+		return wrapError("delete", "autoscaler", name, err)
+	}
+
+	fmt.Printf("Deleted autoscaler %q.\n", name)
+	return nil
+}
@@ -0,0 +1,61 @@
+package orchestrator
+
+import (
+	"testing"
+
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+func TestAutoscalerCreate(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	autoscalers := NewAutoscalerOrchestrator(server.ClientSet(), "default")
+
+	if err := autoscalers.Create(AutoscalerSpec{Deployment: "web", MaxReplicas: 5}); !IsNotFound(err) {
+		t.Errorf("Create for a missing deployment: got %v, want a NotFound error", err)
+	}
+
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); err != nil {
+		t.Fatalf("Create deployment: %v", err)
+	}
+
+	if err := autoscalers.Create(AutoscalerSpec{Deployment: "web", MaxReplicas: 5}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var autoscaler autoscalingv1.HorizontalPodAutoscaler
+	if err := server.Get("horizontalpodautoscalers", "default", "web", &autoscaler); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	target := autoscaler.Spec.ScaleTargetRef
+	if target.Kind != "Deployment" || target.Name != "web" || target.APIVersion != AppsV1beta2 {
+		t.Errorf("got scale target %+v, want deployment web through %s", target, AppsV1beta2)
+	}
+}
+
+func TestAutoscalerCustomMetricsNeedV2alpha1(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	server.SetGroupVersions("v1", "apps/v1beta2", "autoscaling/v1")
+
+	autoscalers := NewAutoscalerOrchestrator(server.ClientSet(), "default")
+	spec := AutoscalerSpec{
+		Deployment:    "web",
+		MaxReplicas:   5,
+		CustomMetrics: map[string]string{"requests_per_second": "10"},
+	}
+
+	if err := autoscalers.Create(spec); !IsInvalid(err) {
+		t.Errorf("Create: got %v, want an Invalid error", err)
+	}
+
+	if err := autoscalers.Update(spec); !IsInvalid(err) {
+		t.Errorf("Update: got %v, want an Invalid error", err)
+	}
+}
@@ -0,0 +1,192 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	apiBatchv1 "k8s.io/api/batch/v1"
+	apiBatchv2alpha1 "k8s.io/api/batch/v2alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+	batchv2alpha1client "k8s.io/client-go/kubernetes/typed/batch/v2alpha1"
+)
+
+// batchV2alpha1 is the API version cron jobs need. It is alpha, so off
+// unless enabled on the API server.
+const batchV2alpha1 = "batch/v2alpha1"
+
+type CronJobOrchestrator struct {
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+}
+
+func NewCronJobOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *CronJobOrchestrator {
+	return &CronJobOrchestrator{
+		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
+	}
+}
+
+// Create creates a cron job running the spec's job on its schedule. The
+// spec must name the cron job.
+func (c CronJobOrchestrator) Create(spec CronJobSpec) error {
+	if spec.Name == "" {
+		return newError("create", "cron job", "", ReasonInvalid, errors.New("name not specified"))
+	}
+
+	spec.JobSpec = spec.JobSpec.withDefaults()
+
+	cronJobSpec, err := spec.cronJobSpec()
+	if err != nil {
+		return newError("create", "cron job", spec.Name, ReasonInvalid, err)
+	}
+
+	cronJob := &apiBatchv2alpha1.CronJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: spec.Name,
+		},
+		Spec: cronJobSpec,
+	}
+
+	cronJobsClient, err := c.cronJobs(c.Namespace)
+	if err != nil {
+		return wrapError("create", "cron job", spec.Name, err)
+	}
+
+	fmt.Println("Creating cron job...")
+	result, err := cronJobsClient.Create(cronJob)
+	if err != nil {
+		return wrapError("create", "cron job", spec.Name, err)
+	}
+
+	fmt.Printf("Created cron job %q scheduled %q.\n", result.Name, result.Spec.Schedule)
+	return nil
+}
+
+// List returns the cron jobs selected by the options.
+func (c CronJobOrchestrator) List(options ListOptions) ([]apiBatchv2alpha1.CronJob, error) {
+	cronJobsClient, err := c.cronJobs(listNamespace(c.Namespace, options.AllNamespaces))
+	if err != nil {
+		return nil, wrapError("list", "cron jobs", "", err)
+	}
+
+	var cronJobs []apiBatchv2alpha1.CronJob
+	err = listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := cronJobsClient.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		cronJobs = append(cronJobs, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, wrapError("list", "cron jobs", "", err)
+	}
+
+	return cronJobs, nil
+}
+
+// Suspend stops the cron job from scheduling new jobs. Jobs already
+// running are left alone.
+func (c CronJobOrchestrator) Suspend(name string) error {
+	return c.setSuspend("suspend", "suspended", name, true)
+}
+
+// Resume lets a suspended cron job schedule jobs again.
+func (c CronJobOrchestrator) Resume(name string) error {
+	return c.setSuspend("resume", "resumed", name, false)
+}
+
+func (c CronJobOrchestrator) setSuspend(op, done, name string, suspend bool) error {
+	cronJobsClient, err := c.cronJobs(c.Namespace)
+	if err != nil {
+		return wrapError(op, "cron job", name, err)
+	}
+
+	patch := []byte(fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend))
+	if _, err := cronJobsClient.Patch(name, types.StrategicMergePatchType, patch); err != nil {
+		return wrapError(op, "cron job", name, err)
+	}
+
+	fmt.Printf("Cron job %q %s.\n", name, done)
+	return nil
+}
+
+// Delete deletes the cron job along with the jobs and pods it created.
+func (c CronJobOrchestrator) Delete(name string) error {
+	cronJobsClient, err := c.cronJobs(c.Namespace)
+	if err != nil {
+		return wrapError("delete", "cron job", name, err)
+	}
+
+	fmt.Println("Deleting cron job...")
+
+	deletePolicy := metav1.DeletePropagationForeground
+	if err := cronJobsClient.Delete(name, &metav1.DeleteOptions{
+		PropagationPolicy: &deletePolicy,
+	}); err != nil {
+		return wrapError("delete", "cron job", name, err)
+	}
+
+	fmt.Println("Deleted cron job.")
+	return nil
+}
+
+// Trigger runs the cron job right away, outside of its schedule, by
+// creating a job from its job template. The job is owned by the cron job,
+// so it is deleted along with it. It returns the name of the job.
+func (c CronJobOrchestrator) Trigger(name string) (string, error) {
+	cronJobsClient, err := c.cronJobs(c.Namespace)
+	if err != nil {
+		return "", wrapError("trigger", "cron job", name, err)
+	}
+
+	cronJob, err := cronJobsClient.Get(name, metav1.GetOptions{})
+	if err != nil {
+		return "", wrapError("trigger", "cron job", name, err)
+	}
+
+	annotations := map[string]string{"cronjob.kubernetes.io/instantiate": "manual"}
+	for key, value := range cronJob.Spec.JobTemplate.Annotations {
+		annotations[key] = value
+	}
+
+	job := &apiBatchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        fmt.Sprintf("%s-manual-%d", name, time.Now().Unix()),
+			Labels:      cronJob.Spec.JobTemplate.Labels,
+			Annotations: annotations,
+			OwnerReferences: []metav1.OwnerReference{
+				*metav1.NewControllerRef(cronJob, apiBatchv2alpha1.SchemeGroupVersion.WithKind("CronJob")),
+			},
+		},
+		Spec: cronJob.Spec.JobTemplate.Spec,
+	}
+
+	result, err := c.KubernetesClientSet.BatchV1().Jobs(c.Namespace).Create(job)
+	if err != nil {
+		return "", wrapError("trigger", "cron job", name, err)
+	}
+
+	fmt.Printf("Job %s created from cron job %q\n", result.Name, name)
+	return result.Name, nil
+}
+
+// cronJobs returns the cron jobs client of a namespace, once discovery
+// has shown the server serves batch/v2alpha1.
+func (c CronJobOrchestrator) cronJobs(namespace string) (batchv2alpha1client.CronJobInterface, error) {
+	served, err := servesGroupVersion(c.KubernetesClientSet, batchV2alpha1)
+	if err != nil {
+		return nil, wrapError("discover", "API groups", "", err)
+	}
+
+	if !served {
+		return nil, newError("use", "API version", batchV2alpha1, ReasonInvalid,
+			fmt.Errorf("the server does not serve it; cron jobs need it enabled on the API server with --runtime-config=%s=true", batchV2alpha1))
+	}
+
+	return c.KubernetesClientSet.BatchV2alpha1().CronJobs(namespace), nil
+}
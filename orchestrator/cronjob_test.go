@@ -0,0 +1,56 @@
+package orchestrator
+
+import (
+	"testing"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+func TestCronJobCreateListDelete(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	cronJobs := NewCronJobOrchestrator(server.ClientSet(), "default")
+
+	if err := cronJobs.Create(CronJobSpec{Schedule: "0 2 * * *"}); !IsInvalid(err) {
+		t.Errorf("Create without a name: got %v, want an Invalid error", err)
+	}
+
+	if err := cronJobs.Create(CronJobSpec{JobSpec: JobSpec{Name: "nightly"}, Schedule: "0 2 * * *"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	list, err := cronJobs.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != "nightly" || list[0].Spec.Schedule != "0 2 * * *" {
+		t.Fatalf("List: got %d cron jobs, want only nightly", len(list))
+	}
+
+	if err := cronJobs.Delete("nightly"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if list, err := cronJobs.List(ListOptions{}); err != nil || len(list) != 0 {
+		t.Errorf("List after Delete: got %d cron jobs and error %v, want none", len(list), err)
+	}
+}
+
+func TestCronJobNeedsBatchV2alpha1(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	server.SetGroupVersions("v1", "apps/v1beta2", "batch/v1")
+
+	cronJobs := NewCronJobOrchestrator(server.ClientSet(), "default")
+
+	if err := cronJobs.Create(CronJobSpec{JobSpec: JobSpec{Name: "nightly"}, Schedule: "0 2 * * *"}); !IsInvalid(err) {
+		t.Errorf("Create: got %v, want an Invalid error", err)
+	}
+
+	if _, err := cronJobs.List(ListOptions{}); !IsInvalid(err) {
+		t.Errorf("List: got %v, want an Invalid error", err)
+	}
+}
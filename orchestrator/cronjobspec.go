@@ -0,0 +1,77 @@
+package orchestrator
+
+import (
+	"errors"
+	"io/ioutil"
+
+	yaml "gopkg.in/yaml.v2"
+	apiBatchv2alpha1 "k8s.io/api/batch/v2alpha1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CronJobSpec describes the cron job CronJobOrchestrator.Create creates:
+// a JobSpec run on a schedule. It can be loaded from a YAML file with
+// LoadCronJobSpec, using the JobSpec fields plus:
+//
+//	schedule: "0 2 * * *"
+//	concurrencyPolicy: Forbid
+//	startingDeadlineSeconds: 300
+//	successfulJobsHistoryLimit: 3
+//	failedJobsHistoryLimit: 1
+type CronJobSpec struct {
+	// JobSpec describes the jobs the cron job creates. Unlike Run, the
+	// cron job is named after its Name as is, so Name is required.
+	JobSpec `yaml:",inline"`
+
+	// Schedule is in cron format, e.g. "0 2 * * *".
+	Schedule string `yaml:"schedule"`
+
+	// ConcurrencyPolicy is Allow, Forbid or Replace. Empty means Allow.
+	ConcurrencyPolicy string `yaml:"concurrencyPolicy"`
+
+	StartingDeadlineSeconds    *int64 `yaml:"startingDeadlineSeconds"`
+	SuccessfulJobsHistoryLimit *int32 `yaml:"successfulJobsHistoryLimit"`
+	FailedJobsHistoryLimit     *int32 `yaml:"failedJobsHistoryLimit"`
+}
+
+// LoadCronJobSpec reads a CronJobSpec from a YAML file.
+func LoadCronJobSpec(path string) (CronJobSpec, error) {
+	spec := CronJobSpec{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return spec, wrapError("read", "cron job spec", path, err)
+	}
+
+	if err := yaml.UnmarshalStrict(content, &spec); err != nil {
+		return spec, wrapError("decode", "cron job spec", path, err)
+	}
+
+	return spec, nil
+}
+
+// cronJobSpec builds the batch CronJobSpec running the spec's job template.
+func (s CronJobSpec) cronJobSpec() (apiBatchv2alpha1.CronJobSpec, error) {
+	if s.Schedule == "" {
+		return apiBatchv2alpha1.CronJobSpec{}, errors.New("schedule not specified")
+	}
+
+	jobSpec, err := s.JobSpec.template()
+	if err != nil {
+		return apiBatchv2alpha1.CronJobSpec{}, err
+	}
+
+	return apiBatchv2alpha1.CronJobSpec{
+		Schedule:                   s.Schedule,
+		ConcurrencyPolicy:          apiBatchv2alpha1.ConcurrencyPolicy(s.ConcurrencyPolicy),
+		StartingDeadlineSeconds:    s.StartingDeadlineSeconds,
+		SuccessfulJobsHistoryLimit: s.SuccessfulJobsHistoryLimit,
+		FailedJobsHistoryLimit:     s.FailedJobsHistoryLimit,
+		JobTemplate: apiBatchv2alpha1.JobTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: jobSpec.Template.Labels,
+			},
+			Spec: jobSpec,
+		},
+	}, nil
+}
@@ -1,51 +1,120 @@
 package orchestrator
 
 import (
+	"errors"
 	"fmt"
+	"sync"
+	"time"
 
-	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
 	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 )
 
+const scaleTimeout = 5 * time.Minute
+
 type DeploymentOrchestrator struct {
-	KubernetesClientSet *kubernetes.Clientset
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+
+	// APIVersion is the deployment API version to use, AppsV1beta2 or
+	// AppsV1beta1. Empty means the newest one the server supports, found
+	// through discovery.
+	APIVersion string
+
+	// DryRun makes Create only print the deployment it would send. It
+	// still fails, as Create would, when one by that name exists.
+	DryRun bool
+
+	// discovered caches the API version found through discovery when
+	// APIVersion is empty. Orchestrators not built by
+	// NewDeploymentOrchestrator run discovery on every call instead.
+	discovered *discoveredVersion
+}
+
+type discoveredVersion struct {
+	mu      sync.Mutex
+	version string
 }
 
-func NewDeploymentOrchestrator(kubernetesClientSet *kubernetes.Clientset) *DeploymentOrchestrator {
+func NewDeploymentOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *DeploymentOrchestrator {
 	return &DeploymentOrchestrator{
 		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
+		discovered:          &discoveredVersion{},
 	}
 }
 
-func (d DeploymentOrchestrator) Create(deployName, appName string, appPort int) {
-	var replicas int32 = 1
+// apiVersion returns APIVersion or, when it is empty, the version the
+// server prefers, asking for it once.
+func (d DeploymentOrchestrator) apiVersion() (string, error) {
+	if d.APIVersion != "" {
+		return d.APIVersion, nil
+	}
+
+	if d.discovered == nil {
+		return PreferredDeploymentVersion(d.KubernetesClientSet)
+	}
+
+	d.discovered.mu.Lock()
+	defer d.discovered.mu.Unlock()
+
+	if d.discovered.version == "" {
+		version, err := PreferredDeploymentVersion(d.KubernetesClientSet)
+		if err != nil {
+			return "", err
+		}
+		d.discovered.version = version
+	}
+
+	return d.discovered.version, nil
+}
+
+// deployments returns a deployments client for the namespace, speaking
+// the orchestrator's API version.
+func (d DeploymentOrchestrator) deployments(namespace string) (deploymentsClient, error) {
+	apiVersion, err := d.apiVersion()
+	if err != nil {
+		return nil, err
+	}
+
+	return newDeploymentsClient(d.KubernetesClientSet, namespace, apiVersion)
+}
+
+// Create creates a deployment running a single container, as described by
+// the spec.
+func (d DeploymentOrchestrator) Create(spec DeploymentSpec) error {
+	spec = spec.withDefaults()
+
+	resources, err := spec.Resources.requirements()
+	if err != nil {
+		return newError("create", "deployment", spec.Name, ReasonInvalid, err)
+	}
 
-	deployment := &appsv1beta1.Deployment{
+	deployment := &appsv1beta2.Deployment{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: deployName,
+			Name: spec.Name,
 		},
-		Spec: appsv1beta1.DeploymentSpec{
-			Replicas: &replicas,
+		Spec: appsv1beta2.DeploymentSpec{
+			Replicas: spec.Replicas,
+			Selector: &metav1.LabelSelector{MatchLabels: spec.Labels},
 			Template: apiv1.PodTemplateSpec{
 				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "demo",
-					},
+					Labels: spec.Labels,
 				},
 				Spec: apiv1.PodSpec{
 					Containers: []apiv1.Container{
 						{
-							Name:  appName,
-							Image: "nginx:1.13",
-							Ports: []apiv1.ContainerPort{
-								{
-									Name:          "http",
-									Protocol:      apiv1.ProtocolTCP,
-									ContainerPort: int32(appPort),
-								},
-							},
+							Name:      spec.AppName,
+							Image:     spec.Image,
+							Ports:     containerPorts(spec.Port),
+							Env:       envVars(spec.Env),
+							Resources: resources,
 						},
 					},
 				},
@@ -53,19 +122,219 @@ func (d DeploymentOrchestrator) Create(deployName, appName string, appPort int)
 		},
 	}
 
-	deploymentsClient := d.KubernetesClientSet.AppsV1beta1().Deployments(apiv1.NamespaceDefault)
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("create", "deployment", spec.Name, err)
+	}
+
+	if d.DryRun {
+		return printDryRunCreate("deployment", spec.Name, deployment, func() (metav1.Object, error) {
+			return deploymentsClient.Get(spec.Name, metav1.GetOptions{})
+		})
+	}
 
 	// Create Deployment
 	fmt.Println("Creating deployment...")
 	result, err := deploymentsClient.Create(deployment)
 	if err != nil {
-		panic(err)
+		return wrapError("create", "deployment", spec.Name, err)
 	}
 	fmt.Printf("Created deployment %q.\n", result.GetObjectMeta().GetName())
+	return nil
+}
+
+// Update applies the non-empty fields of the spec to an existing
+// deployment: image, port, replicas, labels, environment and resources.
+// The selector follows the labels on apps/v1beta1 only; apps/v1beta2
+// makes it immutable, so there the new labels must still match it.
+func (d DeploymentOrchestrator) Update(spec DeploymentSpec) error {
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("update", "deployment", spec.Name, err)
+	}
+	_, speaksV1beta1 := deploymentsClient.(v1beta1Deployments)
+
+	// Get-modify-update, retrying when someone else changed the deployment in between.
+	fmt.Println("Updating deployment...")
+	err = retryOnConflict(func() error {
+		deployment, err := deploymentsClient.Get(spec.Name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		container, err := findContainer(deployment.Spec.Template.Spec.Containers, spec.AppName)
+		if err != nil {
+			return newError("update", "deployment", spec.Name, ReasonNotFound, err)
+		}
+
+		if spec.Image != "" {
+			container.Image = spec.Image
+		}
+
+		if spec.Port > 0 {
+			container.Ports = containerPorts(spec.Port)
+		}
+
+		if len(spec.Env) > 0 {
+			container.Env = envVars(spec.Env)
+		}
+
+		if len(spec.Resources.Requests) > 0 || len(spec.Resources.Limits) > 0 {
+			resources, err := spec.Resources.requirements()
+			if err != nil {
+				return newError("update", "deployment", spec.Name, ReasonInvalid, err)
+			}
+			container.Resources = resources
+		}
+
+		if spec.Replicas != nil {
+			deployment.Spec.Replicas = spec.Replicas
+		}
+
+		if len(spec.Labels) > 0 {
+			deployment.Spec.Template.ObjectMeta.Labels = spec.Labels
+			if speaksV1beta1 {
+				deployment.Spec.Selector = &metav1.LabelSelector{MatchLabels: spec.Labels}
+			} else if err := selectorMatches(deployment.Spec.Selector, spec.Labels); err != nil {
+				return newError("update", "deployment", spec.Name, ReasonInvalid, err)
+			}
+		}
+
+		_, err = deploymentsClient.Update(deployment)
+		return err
+	})
+	if err != nil {
+		return wrapError("update", "deployment", spec.Name, err)
+	}
+
+	fmt.Printf("Updated deployment %q.\n", spec.Name)
+	return nil
+}
+
+// selectorMatches checks that the labels are still selected by the
+// immutable selector of an apps/v1beta2 deployment.
+func selectorMatches(selector *metav1.LabelSelector, podLabels map[string]string) error {
+	parsed, err := metav1.LabelSelectorAsSelector(selector)
+	if err != nil {
+		return err
+	}
+
+	if !parsed.Matches(labels.Set(podLabels)) {
+		return fmt.Errorf("labels %v do not match the selector %q, which %s deployments cannot change", podLabels, parsed.String(), AppsV1beta2)
+	}
+
+	return nil
+}
+
+func findContainer(containers []apiv1.Container, name string) (*apiv1.Container, error) {
+	for i := range containers {
+		if containers[i].Name == name {
+			return &containers[i], nil
+		}
+	}
+
+	return nil, fmt.Errorf("container %q not found", name)
+}
+
+// Scale sets the number of replicas of a deployment and waits until that
+// many pods are available, or scaleTimeout expires.
+func (d DeploymentOrchestrator) Scale(deployName string, replicas int32) error {
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("scale", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Scaling deployment %q to %d replicas...\n", deployName, replicas)
+	patch := []byte(fmt.Sprintf(`{"spec":{"replicas":%d}}`, replicas))
+	deployment, err := deploymentsClient.Patch(deployName, types.StrategicMergePatchType, patch)
+	if err != nil {
+		return wrapError("scale", "deployment", deployName, err)
+	}
+
+	err = d.waitFor(deployment, scaleTimeout, func(deployment *appsv1beta2.Deployment) (bool, error) {
+		fmt.Printf("%d of %d replicas available\n", deployment.Status.AvailableReplicas, replicas)
+		return deployment.Status.ObservedGeneration >= deployment.Generation &&
+			deployment.Status.Replicas == replicas &&
+			deployment.Status.AvailableReplicas == replicas, nil
+	})
+	if err != nil {
+		return wrapError("scale", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Scaled deployment %q.\n", deployName)
+	return nil
+}
+
+// waitFor watches the deployment, starting from the given revision of it,
+// until done returns true or an error for one of its states, or the timeout
+// expires.
+func (d DeploymentOrchestrator) waitFor(deployment *appsv1beta2.Deployment, timeout time.Duration, done func(*appsv1beta2.Deployment) (bool, error)) error {
+	if finished, err := done(deployment); finished || err != nil {
+		return err
+	}
+
+	deploymentsClient, err := d.deployments(deployment.Namespace)
+	if err != nil {
+		return err
+	}
+
+	deadline := time.After(timeout)
+	resourceVersion := deployment.ResourceVersion
+
+	for {
+		watcher, err := deploymentsClient.Watch(metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + deployment.Name,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			return err
+		}
+
+		finished, err := watchDeployment(watcher, deadline, deployment.Name, &resourceVersion, done)
+		watcher.Stop()
+		if finished || err != nil {
+			return err
+		}
+	}
 }
 
-func (d DeploymentOrchestrator) Delete(deployName string) {
-	deploymentsClient := d.KubernetesClientSet.AppsV1beta1().Deployments(apiv1.NamespaceDefault)
+// watchDeployment consumes a single watch stream. It returns false without
+// error when the server closed the stream and the caller should watch again.
+func watchDeployment(watcher watch.Interface, deadline <-chan time.Time, deployName string, resourceVersion *string, done func(*appsv1beta2.Deployment) (bool, error)) (bool, error) {
+	for {
+		select {
+		case <-deadline:
+			return true, newError("wait for", "deployment", deployName, ReasonTimeout, errors.New("timed out"))
+		case event, open := <-watcher.ResultChan():
+			if !open {
+				return false, nil
+			}
+
+			switch event.Type {
+			case watch.Error:
+				return true, apierrors.FromObject(event.Object)
+			case watch.Deleted:
+				return true, newError("wait for", "deployment", deployName, ReasonNotFound, errors.New("deployment was deleted"))
+			}
+
+			deployment, parsed := event.Object.(*appsv1beta2.Deployment)
+			if !parsed {
+				continue
+			}
+
+			*resourceVersion = deployment.ResourceVersion
+			if finished, err := done(deployment); finished || err != nil {
+				return true, err
+			}
+		}
+	}
+}
+
+func (d DeploymentOrchestrator) Delete(deployName string) error {
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("delete", "deployment", deployName, err)
+	}
 
 	fmt.Println("Deleting deployment...")
 
@@ -73,21 +342,45 @@ func (d DeploymentOrchestrator) Delete(deployName string) {
 	if err := deploymentsClient.Delete(deployName, &metav1.DeleteOptions{
 		PropagationPolicy: &deletePolicy,
 	}); err != nil {
-		fmt.Println("Error on delete deployment. Error: ", err.Error())
+		return wrapError("delete", "deployment", deployName, err)
 	}
 
 	fmt.Println("Deleted deployment.")
+	return nil
 }
 
-func (d DeploymentOrchestrator) List() {
-	deploymentsClient := d.KubernetesClientSet.AppsV1beta1().Deployments(apiv1.NamespaceDefault)
+// List returns the deployments selected by the options. Whatever the API
+// version they were listed with, they come as apps/v1beta2 objects, their
+// TypeMeta naming the version actually used.
+func (d DeploymentOrchestrator) List(options ListOptions) ([]appsv1beta2.Deployment, error) {
+	apiVersion, err := d.apiVersion()
+	if err != nil {
+		return nil, wrapError("list", "deployments", "", err)
+	}
 
-	fmt.Printf("Listing deployments in namespace %q:\n", apiv1.NamespaceDefault)
-	list, err := deploymentsClient.List(metav1.ListOptions{})
+	deploymentsClient, err := newDeploymentsClient(d.KubernetesClientSet, listNamespace(d.Namespace, options.AllNamespaces), apiVersion)
 	if err != nil {
-		panic(err)
+		return nil, wrapError("list", "deployments", "", err)
 	}
-	for _, d := range list.Items {
-		fmt.Printf(" * %s (%d replicas)\n", d.Name, *d.Spec.Replicas)
+
+	var deployments []appsv1beta2.Deployment
+	err = listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := deploymentsClient.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		deployments = append(deployments, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, wrapError("list", "deployments", "", err)
 	}
+
+	for i := range deployments {
+		deployments[i].Kind = "Deployment"
+		deployments[i].APIVersion = apiVersion
+	}
+
+	return deployments, nil
 }
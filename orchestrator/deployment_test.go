@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"testing"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+func TestDeploymentCreateListDelete(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); !IsAlreadyExists(err) {
+		t.Errorf("Create of an existing deployment: got %v, want an AlreadyExists error", err)
+	}
+
+	list, err := deployments.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != "web" {
+		t.Fatalf("List: got %d deployments, want only web", len(list))
+	}
+
+	if list[0].APIVersion != AppsV1beta2 {
+		t.Errorf("List: got API version %q, want %s", list[0].APIVersion, AppsV1beta2)
+	}
+
+	containers := list[0].Spec.Template.Spec.Containers
+	if len(containers) != 1 || containers[0].Name != "web" || containers[0].Image != defaultImage {
+		t.Errorf("List: got containers %+v, want one web container running %s", containers, defaultImage)
+	}
+
+	if err := deployments.Delete("web"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if list, err := deployments.List(ListOptions{}); err != nil || len(list) != 0 {
+		t.Errorf("List after Delete: got %d deployments and error %v, want none", len(list), err)
+	}
+
+	if err := deployments.Delete("web"); !IsNotFound(err) {
+		t.Errorf("Delete of a deleted deployment: got %v, want a NotFound error", err)
+	}
+}
+
+func TestDeploymentCreateOnAppsV1beta1(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	server.SetGroupVersions("v1", "apps/v1beta1", "extensions/v1beta1")
+
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var deployment appsv1beta1.Deployment
+	if err := server.Get("deployments", "default", "web", &deployment); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if got := deployment.Spec.Template.Labels["app"]; got != "web" {
+		t.Errorf("got pod label app=%q, want app=web", got)
+	}
+
+	list, err := deployments.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != "web" {
+		t.Fatalf("List: got %d deployments, want only web", len(list))
+	}
+
+	if list[0].APIVersion != AppsV1beta1 {
+		t.Errorf("List: got API version %q, want %s", list[0].APIVersion, AppsV1beta1)
+	}
+}
+
+func TestDeploymentCreateDryRun(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+	deployments.DryRun = true
+
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	var deployment appsv1beta1.Deployment
+	if err := server.Get("deployments", "default", "web", &deployment); err == nil {
+		t.Fatal("a dry run created the deployment")
+	}
+
+	deployments.DryRun = false
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web"}); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	deployments.DryRun = true
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web", Image: "nginx:1.15"}); !IsAlreadyExists(err) {
+		t.Errorf("dry run Create of an existing deployment: got %v, want an AlreadyExists error", err)
+	}
+}
@@ -0,0 +1,193 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	appsv1beta1 "k8s.io/api/apps/v1beta1"
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	appsv1beta1client "k8s.io/client-go/kubernetes/typed/apps/v1beta1"
+)
+
+// Deployment API versions the orchestrators can use.
+const (
+	AppsV1beta2 = "apps/v1beta2"
+	AppsV1beta1 = "apps/v1beta1"
+)
+
+// deploymentVersions lists the deployment API versions, newest first.
+var deploymentVersions = []string{AppsV1beta2, AppsV1beta1}
+
+// deploymentsClient is the part of a typed deployments client the
+// orchestrators use. It deals in apps/v1beta2 objects whatever version it
+// speaks to the server.
+type deploymentsClient interface {
+	Create(*appsv1beta2.Deployment) (*appsv1beta2.Deployment, error)
+	Update(*appsv1beta2.Deployment) (*appsv1beta2.Deployment, error)
+	Delete(name string, options *metav1.DeleteOptions) error
+	Get(name string, options metav1.GetOptions) (*appsv1beta2.Deployment, error)
+	List(opts metav1.ListOptions) (*appsv1beta2.DeploymentList, error)
+	Watch(opts metav1.ListOptions) (watch.Interface, error)
+	Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*appsv1beta2.Deployment, error)
+}
+
+// PreferredDeploymentVersion asks the server, through discovery, for the
+// newest deployment API version both it and the orchestrators support.
+func PreferredDeploymentVersion(clientSet kubernetes.Interface) (string, error) {
+	groups, err := clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return "", wrapError("discover", "API groups", "", err)
+	}
+
+	version := preferredDeploymentVersion(groupVersions(groups))
+	if version == "" {
+		return "", newError("discover", "API groups", "", ReasonNotFound,
+			fmt.Errorf("the server supports none of %s", strings.Join(deploymentVersions, ", ")))
+	}
+
+	return version, nil
+}
+
+// preferredDeploymentVersion returns the newest deployment API version
+// among the served group versions, or an empty string.
+func preferredDeploymentVersion(served []string) string {
+	for _, version := range deploymentVersions {
+		for _, groupVersion := range served {
+			if groupVersion == version {
+				return version
+			}
+		}
+	}
+
+	return ""
+}
+
+func groupVersions(groups *metav1.APIGroupList) []string {
+	served := []string{}
+	for _, group := range groups.Groups {
+		for _, version := range group.Versions {
+			served = append(served, version.GroupVersion)
+		}
+	}
+
+	return served
+}
+
+// newDeploymentsClient returns a deployments client speaking the given API
+// version, or the preferred one when it is empty.
+func newDeploymentsClient(clientSet kubernetes.Interface, namespace, apiVersion string) (deploymentsClient, error) {
+	if apiVersion == "" {
+		var err error
+		if apiVersion, err = PreferredDeploymentVersion(clientSet); err != nil {
+			return nil, err
+		}
+	}
+
+	switch apiVersion {
+	case AppsV1beta2:
+		return clientSet.AppsV1beta2().Deployments(namespace), nil
+	case AppsV1beta1:
+		return v1beta1Deployments{clientSet.AppsV1beta1().Deployments(namespace)}, nil
+	}
+
+	return nil, newError("use", "deployment API version", apiVersion, ReasonInvalid,
+		fmt.Errorf("must be one of %s", strings.Join(deploymentVersions, ", ")))
+}
+
+// v1beta1Deployments speaks apps/v1beta1 to the server. Objects are
+// converted from and to apps/v1beta2 through their JSON form, which both
+// versions share but for the rollbackTo field the orchestrators never set.
+type v1beta1Deployments struct {
+	client appsv1beta1client.DeploymentInterface
+}
+
+func (c v1beta1Deployments) Create(deployment *appsv1beta2.Deployment) (*appsv1beta2.Deployment, error) {
+	converted := &appsv1beta1.Deployment{}
+	if err := convertDeployment(deployment, converted); err != nil {
+		return nil, err
+	}
+
+	return toV1beta2(c.client.Create(converted))
+}
+
+func (c v1beta1Deployments) Update(deployment *appsv1beta2.Deployment) (*appsv1beta2.Deployment, error) {
+	converted := &appsv1beta1.Deployment{}
+	if err := convertDeployment(deployment, converted); err != nil {
+		return nil, err
+	}
+
+	return toV1beta2(c.client.Update(converted))
+}
+
+func (c v1beta1Deployments) Delete(name string, options *metav1.DeleteOptions) error {
+	return c.client.Delete(name, options)
+}
+
+func (c v1beta1Deployments) Get(name string, options metav1.GetOptions) (*appsv1beta2.Deployment, error) {
+	return toV1beta2(c.client.Get(name, options))
+}
+
+func (c v1beta1Deployments) List(opts metav1.ListOptions) (*appsv1beta2.DeploymentList, error) {
+	list, err := c.client.List(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1beta2.DeploymentList{}
+	if err := convertDeployment(list, converted); err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+// Watch converts the deployments of the events. Events it fails to
+// convert are passed on as they are, and ignored by the orchestrators.
+func (c v1beta1Deployments) Watch(opts metav1.ListOptions) (watch.Interface, error) {
+	watcher, err := c.client.Watch(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return watch.Filter(watcher, func(event watch.Event) (watch.Event, bool) {
+		if deployment, ok := event.Object.(*appsv1beta1.Deployment); ok {
+			if converted, err := toV1beta2(deployment, nil); err == nil {
+				event.Object = converted
+			}
+		}
+
+		return event, true
+	}), nil
+}
+
+func (c v1beta1Deployments) Patch(name string, pt types.PatchType, data []byte, subresources ...string) (*appsv1beta2.Deployment, error) {
+	return toV1beta2(c.client.Patch(name, pt, data, subresources...))
+}
+
+// toV1beta2 converts the result of an apps/v1beta1 client call.
+func toV1beta2(deployment *appsv1beta1.Deployment, err error) (*appsv1beta2.Deployment, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	converted := &appsv1beta2.Deployment{}
+	if err := convertDeployment(deployment, converted); err != nil {
+		return nil, err
+	}
+
+	return converted, nil
+}
+
+func convertDeployment(in, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, out)
+}
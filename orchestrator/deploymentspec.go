@@ -0,0 +1,115 @@
+package orchestrator
+
+import (
+	"io/ioutil"
+	"sort"
+
+	yaml "gopkg.in/yaml.v2"
+	apiv1 "k8s.io/api/core/v1"
+)
+
+const (
+	defaultImage    = "nginx:1.13"
+	defaultAppPort  = 8080
+	defaultReplicas = 1
+)
+
+// DeploymentSpec describes the deployment DeploymentOrchestrator.Create
+// builds and the changes DeploymentOrchestrator.Update applies. It can be
+// loaded from a YAML file with LoadDeploymentSpec:
+//
+//	name: web
+//	appName: nginx
+//	image: nginx:1.13
+//	port: 8080
+//	replicas: 2
+//	labels:
+//	  app: nginx
+//	env:
+//	  LOG_LEVEL: debug
+//	resources:
+//	  requests:
+//	    cpu: 100m
+type DeploymentSpec struct {
+	// Name of the deployment.
+	Name string `yaml:"name"`
+
+	// AppName names the container running the application.
+	AppName string `yaml:"appName"`
+
+	Image    string            `yaml:"image"`
+	Port     int               `yaml:"port"`
+	Replicas *int32            `yaml:"replicas"`
+	Labels   map[string]string `yaml:"labels"`
+	Env      map[string]string `yaml:"env"`
+
+	// Resources are the compute resources of the container. A CPU
+	// request is what an autoscaler CPU target is a percentage of.
+	Resources ContainerResources `yaml:"resources"`
+}
+
+// LoadDeploymentSpec reads a DeploymentSpec from a YAML file.
+func LoadDeploymentSpec(path string) (DeploymentSpec, error) {
+	spec := DeploymentSpec{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return spec, wrapError("read", "deployment spec", path, err)
+	}
+
+	if err := yaml.UnmarshalStrict(content, &spec); err != nil {
+		return spec, wrapError("decode", "deployment spec", path, err)
+	}
+
+	return spec, nil
+}
+
+// withDefaults fills in what Create needs but the spec left empty. Pods
+// are labelled with the app name unless labels are given.
+func (s DeploymentSpec) withDefaults() DeploymentSpec {
+	if s.Image == "" {
+		s.Image = defaultImage
+	}
+
+	if s.Port == 0 {
+		s.Port = defaultAppPort
+	}
+
+	if s.Replicas == nil {
+		replicas := int32(defaultReplicas)
+		s.Replicas = &replicas
+	}
+
+	if len(s.Labels) == 0 {
+		s.Labels = map[string]string{"app": s.AppName}
+	}
+
+	return s
+}
+
+func containerPorts(port int) []apiv1.ContainerPort {
+	return []apiv1.ContainerPort{
+		{
+			Name:          "http",
+			Protocol:      apiv1.ProtocolTCP,
+			ContainerPort: int32(port),
+		},
+	}
+}
+
+// envVars converts an environment map into container variables, sorted
+// by name so the pod template does not change between runs.
+func envVars(env map[string]string) []apiv1.EnvVar {
+	names := []string{}
+	for name := range env {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	vars := []apiv1.EnvVar{}
+	for _, name := range names {
+		vars = append(vars, apiv1.EnvVar{Name: name, Value: env[name]})
+	}
+
+	return vars
+}
@@ -0,0 +1,91 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+)
+
+// diffObjects returns a line per field that differs between before and
+// after, using dotted JSON paths such as "spec.containers[0].image".
+func diffObjects(before, after interface{}) ([]string, error) {
+	beforeFields, err := flattenObject(before)
+	if err != nil {
+		return nil, err
+	}
+
+	afterFields, err := flattenObject(after)
+	if err != nil {
+		return nil, err
+	}
+
+	return diffFields(beforeFields, afterFields), nil
+}
+
+// diffFields compares two flattened objects, path by path.
+func diffFields(beforeFields, afterFields map[string]string) []string {
+	paths := []string{}
+	for path := range beforeFields {
+		paths = append(paths, path)
+	}
+	for path := range afterFields {
+		if _, found := beforeFields[path]; !found {
+			paths = append(paths, path)
+		}
+	}
+	sort.Strings(paths)
+
+	lines := []string{}
+	for _, path := range paths {
+		oldValue, hadValue := beforeFields[path]
+		newValue, hasValue := afterFields[path]
+
+		switch {
+		case !hadValue:
+			lines = append(lines, fmt.Sprintf("+ %s: %s", path, newValue))
+		case !hasValue:
+			lines = append(lines, fmt.Sprintf("- %s: %s", path, oldValue))
+		case oldValue != newValue:
+			lines = append(lines, fmt.Sprintf("~ %s: %s -> %s", path, oldValue, newValue))
+		}
+	}
+
+	return lines
+}
+
+// flattenObject maps every leaf of the object's JSON form to its path.
+func flattenObject(object interface{}) (map[string]string, error) {
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(objectJSON, &generic); err != nil {
+		return nil, err
+	}
+
+	fields := map[string]string{}
+	flattenValue("", generic, fields)
+	return fields, nil
+}
+
+func flattenValue(path string, value interface{}, fields map[string]string) {
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typed {
+			childPath := key
+			if path != "" {
+				childPath = path + "." + key
+			}
+			flattenValue(childPath, child, fields)
+		}
+	case []interface{}:
+		for i, child := range typed {
+			flattenValue(fmt.Sprintf("%s[%d]", path, i), child, fields)
+		}
+	default:
+		valueJSON, _ := json.Marshal(typed)
+		fields[path] = string(valueJSON)
+	}
+}
@@ -0,0 +1,164 @@
+package orchestrator
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// printDryRun prints the object an operation would send to the server and,
+// when get finds the object already there, the fields the operation would
+// change. get may be nil for objects that cannot exist yet.
+func printDryRun(op, kind, name string, object interface{}, get liveObject) error {
+	objectYAML, err := toYAML(object)
+	if err != nil {
+		return newError(op, kind, name, ReasonInvalid, err)
+	}
+
+	fmt.Printf("Dry run: would %s %s %q:\n%s", op, kind, name, objectYAML)
+	if get == nil {
+		return nil
+	}
+
+	live, err := get()
+	if errors.IsNotFound(err) {
+		fmt.Printf("%s %q does not exist yet.\n", kind, name)
+		return nil
+	}
+	if err != nil {
+		return wrapError(op, kind, name, err)
+	}
+
+	changes, err := dryRunChanges(live, object)
+	if err != nil {
+		return newError(op, kind, name, ReasonInvalid, err)
+	}
+
+	if len(changes) == 0 {
+		fmt.Printf("No changes to the live %s.\n", kind)
+		return nil
+	}
+
+	fmt.Printf("Changes to the live %s:\n", kind)
+	for _, change := range changes {
+		fmt.Println("    ", change)
+	}
+
+	return nil
+}
+
+// printDryRunCreate prints the object a create would send. It fails as
+// the create would when get finds the object already there.
+func printDryRunCreate(kind, name string, object interface{}, get liveObject) error {
+	if err := printDryRun("create", kind, name, object, nil); err != nil {
+		return err
+	}
+
+	_, err := get()
+	if errors.IsNotFound(err) {
+		return nil
+	}
+	if err != nil {
+		return wrapError("create", kind, name, err)
+	}
+
+	return newError("create", kind, name, ReasonAlreadyExists,
+		fmt.Errorf("%s %q already exists, so the create would fail", kind, name))
+}
+
+// printDryRunDelete prints the live object a delete would remove. It fails
+// as the delete would when the object does not exist.
+func printDryRunDelete(kind, name string, get liveObject) error {
+	live, err := get()
+	if err != nil {
+		return wrapError("delete", kind, name, err)
+	}
+
+	liveYAML, err := toYAML(live)
+	if err != nil {
+		return newError("delete", kind, name, ReasonInvalid, err)
+	}
+
+	fmt.Printf("Dry run: would delete %s %q:\n%s", kind, name, liveYAML)
+	return nil
+}
+
+// dryRunChanges diffs the live object against the one that would be sent.
+// Fields the sent object leaves unset are not reported as removed, since
+// the server fills in their defaults and owns the status and most of the
+// metadata. Elements of the lists it sets are, as the list is replaced.
+func dryRunChanges(live, object interface{}) ([]string, error) {
+	liveFields, err := flattenObject(live)
+	if err != nil {
+		return nil, err
+	}
+
+	objectFields, err := flattenObject(object)
+	if err != nil {
+		return nil, err
+	}
+
+	for path, value := range objectFields {
+		if value == "null" || path == "kind" || path == "apiVersion" {
+			delete(objectFields, path)
+		}
+	}
+
+	for path := range liveFields {
+		_, set := objectFields[path]
+		if !set && !removedElement(path, objectFields) {
+			delete(liveFields, path)
+		}
+	}
+
+	return diffFields(liveFields, objectFields), nil
+}
+
+// removedElement reports whether the path lies in a list element the
+// fields no longer have, while they still set the list.
+func removedElement(path string, fields map[string]string) bool {
+	for i := strings.Index(path, "["); i >= 0; {
+		end := i + strings.Index(path[i:], "]") + 1
+		list, element := path[:i+1], path[:end]
+		if hasPrefix(fields, list) && !hasPrefix(fields, element) {
+			return true
+		}
+
+		next := strings.Index(path[end:], "[")
+		if next < 0 {
+			break
+		}
+		i = end + next
+	}
+
+	return false
+}
+
+func hasPrefix(fields map[string]string, prefix string) bool {
+	for path := range fields {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// toYAML renders an API object as YAML, with the field names of its JSON
+// form.
+func toYAML(object interface{}) ([]byte, error) {
+	objectJSON, err := json.Marshal(object)
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal(objectJSON, &generic); err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(generic)
+}
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Error is returned by the orchestrators for every failed operation. It
+// wraps the underlying error, usually an apimachinery *StatusError, and
+// classifies it with one of the Reason values below. It implements the
+// apimachinery APIStatus interface, so apierrors.IsNotFound and friends
+// work on it too.
+type Error struct {
+	Op     string
+	Kind   string
+	Name   string
+	Reason metav1.StatusReason
+	Err    error
+}
+
+// Reasons an orchestrator Error is classified with.
+const (
+	ReasonNotFound      = metav1.StatusReasonNotFound
+	ReasonAlreadyExists = metav1.StatusReasonAlreadyExists
+	ReasonConflict      = metav1.StatusReasonConflict
+	ReasonForbidden     = metav1.StatusReasonForbidden
+	ReasonTimeout       = metav1.StatusReasonTimeout
+	ReasonInvalid       = metav1.StatusReasonInvalid
+	ReasonUnknown       = metav1.StatusReasonUnknown
+
+	// ReasonFailed means a job ran to the end without succeeding.
+	ReasonFailed metav1.StatusReason = "Failed"
+
+	// ReasonInterrupted means the caller gave up waiting for an operation.
+	ReasonInterrupted metav1.StatusReason = "Interrupted"
+)
+
+func (e *Error) Error() string {
+	if e.Name == "" {
+		return fmt.Sprintf("%s %s: %s", e.Op, e.Kind, e.Err)
+	}
+
+	return fmt.Sprintf("%s %s %q: %s", e.Op, e.Kind, e.Name, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// Status returns the API status of the underlying error, or one built from
+// the error's reason when it did not come from the API server.
+func (e *Error) Status() metav1.Status {
+	if status, ok := e.Err.(apierrors.APIStatus); ok {
+		return status.Status()
+	}
+
+	return metav1.Status{
+		Status:  metav1.StatusFailure,
+		Reason:  e.Reason,
+		Message: e.Error(),
+	}
+}
+
+// ReasonForError returns the reason of an orchestrator Error, or
+// ReasonUnknown for any other error.
+func ReasonForError(err error) metav1.StatusReason {
+	if orchestratorErr, ok := err.(*Error); ok {
+		return orchestratorErr.Reason
+	}
+
+	return ReasonUnknown
+}
+
+// IsNotFound reports whether the error means the object does not exist.
+func IsNotFound(err error) bool {
+	return ReasonForError(err) == ReasonNotFound
+}
+
+// IsAlreadyExists reports whether the error means the object already exists.
+func IsAlreadyExists(err error) bool {
+	return ReasonForError(err) == ReasonAlreadyExists
+}
+
+// IsConflict reports whether the error means the object was changed concurrently.
+func IsConflict(err error) bool {
+	return ReasonForError(err) == ReasonConflict
+}
+
+// IsForbidden reports whether the error means the operation was not allowed.
+func IsForbidden(err error) bool {
+	return ReasonForError(err) == ReasonForbidden
+}
+
+// IsInvalid reports whether the error means the object was rejected as invalid.
+func IsInvalid(err error) bool {
+	return ReasonForError(err) == ReasonInvalid
+}
+
+// IsTimeout reports whether the error means the operation did not finish in time.
+func IsTimeout(err error) bool {
+	return ReasonForError(err) == ReasonTimeout
+}
+
+// IsFailed reports whether the error means a job ran but did not succeed.
+func IsFailed(err error) bool {
+	return ReasonForError(err) == ReasonFailed
+}
+
+// IsInterrupted reports whether the error means waiting was interrupted.
+func IsInterrupted(err error) bool {
+	return ReasonForError(err) == ReasonInterrupted
+}
+
+// wrapError classifies an error returned while performing op on the named
+// object. It returns nil for a nil error and leaves orchestrator Errors as
+// they are.
+func wrapError(op, kind, name string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	if _, ok := err.(*Error); ok {
+		return err
+	}
+
+	reason := ReasonUnknown
+	switch {
+	case apierrors.IsNotFound(err):
+		reason = ReasonNotFound
+	case apierrors.IsAlreadyExists(err):
+		reason = ReasonAlreadyExists
+	case apierrors.IsConflict(err):
+		reason = ReasonConflict
+	case apierrors.IsForbidden(err):
+		reason = ReasonForbidden
+	case apierrors.IsTimeout(err), apierrors.IsServerTimeout(err):
+		reason = ReasonTimeout
+	case apierrors.IsInvalid(err), apierrors.IsBadRequest(err):
+		reason = ReasonInvalid
+	}
+
+	return &Error{Op: op, Kind: kind, Name: name, Reason: reason, Err: err}
+}
+
+// newError builds an orchestrator Error for failures detected locally,
+// such as a watch that timed out.
+func newError(op, kind, name string, reason metav1.StatusReason, err error) error {
+	return &Error{Op: op, Kind: kind, Name: name, Reason: reason, Err: err}
+}
@@ -2,10 +2,9 @@ package orchestrator
 
 import (
 	"crypto/rand"
-	"encoding/json"
-	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/oklog/ulid"
 	apiBatchv1 "k8s.io/api/batch/v1"
@@ -14,138 +13,181 @@ import (
 	"k8s.io/client-go/kubernetes"
 )
 
+// Label Run sets on the jobs it creates, so Prune leaves the other jobs of
+// a shared namespace alone.
+const (
+	createdByLabel = "created-by"
+	createdByValue = "golang_kubernetes_example"
+)
+
 type JobOrchestrator struct {
-	KubernetesClientSet *kubernetes.Clientset
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+
+	// Logs selects the job output shown by Run.
+	Logs LogOptions
+
+	// Timeout bounds how long Run waits for a job to finish. Zero means
+	// one hour.
+	Timeout time.Duration
+
+	// Cleanup selects the jobs Run deletes once they have finished.
+	// Empty means CleanupNever.
+	Cleanup CleanupPolicy
+
+	// Interrupt, once closed, stops Run and PrintLogs from waiting for the
+	// job. The job itself keeps running.
+	Interrupt <-chan struct{}
+
+	// DryRun makes Run only print the job it would create, returning a
+	// nil result.
+	DryRun bool
 }
 
-func NewJobOrchestrator(kubernetesClientSet *kubernetes.Clientset) *JobOrchestrator {
+func NewJobOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *JobOrchestrator {
 	return &JobOrchestrator{
 		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
 	}
 }
 
-func (j JobOrchestrator) Create() error {
+// Run creates a job as described by the spec, waits for it to complete or
+// fail, prints its output and deletes it if the Cleanup policy says so. A
+// job that failed is reported with its result and a ReasonFailed error.
+// When Interrupt is closed first, Run returns a ReasonInterrupted error and
+// a result holding only the name of the job, which is left running.
+// With DryRun, Run only prints the job.
+func (j JobOrchestrator) Run(spec JobSpec) (*JobResult, error) {
+	spec = spec.withDefaults()
+	if err := spec.validate(); err != nil {
+		return nil, newError("create", "job", spec.Name, ReasonInvalid, err)
+	}
+
 	ulid := ulid.MustNew(ulid.Now(), rand.Reader)
-	jobName := strings.ToLower(fmt.Sprintf("%s-%s", ulid, "job-example"))
+	jobName := fmt.Sprintf("%s-%s", strings.ToLower(ulid.String()), spec.Name)
+
+	jobSpec, err := spec.template()
+	if err != nil {
+		return nil, newError("create", "job", jobName, ReasonInvalid, err)
+	}
 
 	job := &apiBatchv1.Job{
 		ObjectMeta: metav1.ObjectMeta{
-			Name: jobName,
-		},
-		Spec: apiBatchv1.JobSpec{
-			Template: apiv1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{
-						"app": "job-demo",
-					},
-				},
-				Spec: apiv1.PodSpec{
-					Containers: []apiv1.Container{
-						{
-							Name:    "docker-hello-world-ubuntu",
-							Image:   "ubuntu:latest",
-							Command: []string{"echo", "Hello World!"},
-						},
-					},
-					RestartPolicy: "Never",
-				},
-			},
+			Name:   jobName,
+			Labels: map[string]string{createdByLabel: createdByValue},
 		},
+		Spec: jobSpec,
 	}
 
-	jobInterface := j.KubernetesClientSet.Jobs(apiv1.NamespaceDefault)
-	jobCreated, err := jobInterface.Create(job)
-	if err != nil {
-		fmt.Printf("Error on create %s Job. Error: %s", jobName, err.Error())
-		return err
+	if j.DryRun {
+		return nil, printDryRun("create", "job", jobName, job, nil)
 	}
 
-	fmt.Printf("Job %s created with success\n", jobCreated.Name)
-	jobOutput, err := j.getJobOutput(jobCreated.Name)
+	jobInterface := j.KubernetesClientSet.BatchV1().Jobs(j.Namespace)
+	jobCreated, err := jobInterface.Create(job)
 	if err != nil {
-		fmt.Println("Error on get logs: ", err.Error())
-		return err
+		return nil, wrapError("create", "job", jobName, err)
 	}
 
-	fmt.Println("Job output: \n", jobOutput)
-	return nil
-}
+	fmt.Printf("Job %s created with success\n", jobCreated.Name)
 
-func (j JobOrchestrator) List() {
-	jobList, err := j.KubernetesClientSet.Jobs(apiv1.NamespaceDefault).List(metav1.ListOptions{})
+	result, err := j.await(jobCreated.Name)
 	if err != nil {
-		fmt.Println("Error on get Jobs")
-		return
+		if IsInterrupted(err) {
+			return &JobResult{Name: jobCreated.Name}, err
+		}
+		return nil, err
 	}
 
-	for _, job := range jobList.Items {
-		fmt.Println("Job: ", job.Name)
+	printJobResult(result)
+	if j.Cleanup.applies(result) {
+		if err := j.Delete(jobCreated.Name); err != nil {
+			return result, err
+		}
 	}
-}
-
-func (j JobOrchestrator) getJobOutput(jobName string) (string, error) {
-	jobInterface := j.KubernetesClientSet.Jobs(apiv1.NamespaceDefault)
-	watch, err := jobInterface.Watch(metav1.ListOptions{
-		LabelSelector: "job-name=" + jobName,
-	})
 
-	if err != nil {
-		fmt.Println("Error on watch Jobs")
-		return "", err
+	if !result.Complete {
+		return result, newError("run", "job", jobName, ReasonFailed, fmt.Errorf("%s: %s", result.Reason, result.Message))
 	}
 
-	for result := range watch.ResultChan() {
-		fmt.Println("result.Type: ", result.Type)
+	return result, nil
+}
 
-		jobWatched, parsed := result.Object.(*apiBatchv1.Job)
-		if !parsed {
-			fmt.Println("Error on parse object")
-			continue
-		}
+// await waits for the job to finish, streaming its logs meanwhile when
+// Logs.Follow is set, or printing them once it has finished otherwise.
+func (j JobOrchestrator) await(jobName string) (*JobResult, error) {
+	if j.Logs.Follow {
+		stop := make(chan struct{})
+		streamed := j.followLogs(jobName, stop)
 
-		statusJSON, _ := json.Marshal(jobWatched.Status)
-		fmt.Println("statusJSON: ", string(statusJSON))
+		result, err := j.waitForJob(jobName)
+		close(stop)
+		<-streamed
 
-		if jobWatched.Status.CompletionTime == nil {
-			fmt.Println("Job not finished yet!")
-			continue
-		}
+		return result, wrapError("wait for", "job", jobName, err)
+	}
 
-		if jobWatched.Status.Succeeded == 1 {
-			fmt.Println("Job succeeded!")
-		} else {
-			fmt.Println("Job failed!")
-		}
+	result, err := j.waitForJob(jobName)
+	if err != nil {
+		return nil, wrapError("wait for", "job", jobName, err)
+	}
 
-		watch.Stop()
+	jobOutput, err := j.getJobOutput(jobName)
+	if err != nil {
+		return result, wrapError("get output of", "job", jobName, err)
 	}
 
-	podOutput, err := j.getPodOutput(jobName)
-	return podOutput, err
+	fmt.Println("Job output: \n", jobOutput)
+	return result, nil
 }
 
-func (j JobOrchestrator) getPodOutput(jobName string) (string, error) {
-	podInterface := j.KubernetesClientSet.Pods(apiv1.NamespaceDefault)
-	podList, err := podInterface.List(metav1.ListOptions{
-		LabelSelector: "job-name=" + jobName,
-	})
+// PrintLogs prints the logs of a job by its full name, as printed by Run.
+// With Logs.Follow, it streams them until the job finishes.
+func (j JobOrchestrator) PrintLogs(jobName string) error {
+	if j.Logs.Follow {
+		_, err := j.await(jobName)
+		return err
+	}
 
+	jobOutput, err := j.getJobOutput(jobName)
 	if err != nil {
-		fmt.Println("Error on get pods")
-		return "", err
+		return wrapError("get output of", "job", jobName, err)
 	}
 
-	if len(podList.Items) <= 0 {
-		return "", errors.New("Pod not found")
+	fmt.Println(jobOutput)
+	return nil
+}
+
+func printJobResult(result *JobResult) {
+	if !result.Finished {
+		fmt.Printf("Job %s running: %d active, %d succeeded, %d failed\n", result.Name, result.Active, result.Succeeded, result.Failed)
+	} else if result.Complete {
+		fmt.Printf("Job %s succeeded: %d succeeded, %d failed\n", result.Name, result.Succeeded, result.Failed)
+	} else {
+		fmt.Printf("Job %s failed: %d succeeded, %d failed (%s: %s)\n", result.Name, result.Succeeded, result.Failed, result.Reason, result.Message)
+	}
+	for _, exit := range result.Containers {
+		fmt.Printf("  %s/%s exited with code %d (%s)\n", exit.Pod, exit.Container, exit.ExitCode, exit.Reason)
 	}
+}
 
-	pod := podList.Items[0]
-	logRequest := podInterface.GetLogs(pod.Name, &apiv1.PodLogOptions{})
+// List returns the jobs selected by the options.
+func (j JobOrchestrator) List(options ListOptions) ([]apiBatchv1.Job, error) {
+	jobInterface := j.KubernetesClientSet.BatchV1().Jobs(listNamespace(j.Namespace, options.AllNamespaces))
 
-	logByteArray, err := logRequest.DoRaw()
+	var jobs []apiBatchv1.Job
+	err := listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := jobInterface.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		jobs = append(jobs, list.Items...)
+		return list.Continue, nil
+	})
 	if err != nil {
-		return "", err
+		return nil, wrapError("list", "jobs", "", err)
 	}
 
-	return string(logByteArray), nil
+	return jobs, nil
 }
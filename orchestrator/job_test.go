@@ -0,0 +1,246 @@
+package orchestrator
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	apiBatchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+// jobPod returns a pod of the job that ran a single container to the end.
+func jobPod(jobName, podName string, phase apiv1.PodPhase, exitCode int32, started time.Time) *apiv1.Pod {
+	startTime := metav1.NewTime(started)
+	return &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      podName,
+			Namespace: "default",
+			Labels:    map[string]string{"job-name": jobName},
+		},
+		Spec: apiv1.PodSpec{
+			Containers: []apiv1.Container{{Name: defaultJobName, Image: defaultJobImage}},
+		},
+		Status: apiv1.PodStatus{
+			Phase:     phase,
+			StartTime: &startTime,
+			ContainerStatuses: []apiv1.ContainerStatus{{
+				Name: defaultJobName,
+				State: apiv1.ContainerState{
+					Terminated: &apiv1.ContainerStateTerminated{ExitCode: exitCode, Reason: "Completed"},
+				},
+			}},
+		},
+	}
+}
+
+// finishedJob returns a job that became complete at the given time.
+func finishedJob(name string, labels map[string]string, finished time.Time) *apiBatchv1.Job {
+	return &apiBatchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Spec: apiBatchv1.JobSpec{
+			Template: apiv1.PodTemplateSpec{
+				Spec: apiv1.PodSpec{
+					Containers:    []apiv1.Container{{Name: defaultJobName, Image: defaultJobImage}},
+					RestartPolicy: apiv1.RestartPolicyNever,
+				},
+			},
+		},
+		Status: apiBatchv1.JobStatus{
+			Succeeded: 1,
+			Conditions: []apiBatchv1.JobCondition{{
+				Type:               apiBatchv1.JobComplete,
+				Status:             apiv1.ConditionTrue,
+				LastTransitionTime: metav1.NewTime(finished),
+			}},
+		},
+	}
+}
+
+func TestJobRun(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	// Play the job controller: start a pod for the job and, a moment
+	// later, mark the job complete, so Run has to watch for it.
+	server.OnCreate("jobs", func(job orchestratortest.Object) {
+		jobName := job["metadata"].(map[string]interface{})["name"].(string)
+		podName := jobName + "-x7k2p"
+
+		if err := server.Add("pods", jobPod(jobName, podName, apiv1.PodSucceeded, 0, time.Now())); err != nil {
+			t.Errorf("adding the pod of job %s: %v", jobName, err)
+		}
+		server.SetLogs("default", podName, defaultJobName, "Hello World!")
+
+		go func() {
+			time.Sleep(100 * time.Millisecond)
+			err := server.Modify("jobs", "default", jobName, func(job orchestratortest.Object) {
+				job["status"] = map[string]interface{}{
+					"succeeded": 1,
+					"conditions": []interface{}{map[string]interface{}{
+						"type":               "Complete",
+						"status":             "True",
+						"lastTransitionTime": time.Now().UTC().Format(time.RFC3339),
+					}},
+				}
+			})
+			if err != nil {
+				t.Errorf("completing job %s: %v", jobName, err)
+			}
+		}()
+	})
+
+	jobs := NewJobOrchestrator(server.ClientSet(), "default")
+	jobs.Timeout = 10 * time.Second
+
+	result, err := jobs.Run(JobSpec{})
+	if err != nil {
+		t.Fatalf("Run: %v", err)
+	}
+
+	if !result.Finished || !result.Complete || result.Succeeded != 1 {
+		t.Errorf("Run: got result %+v, want a complete job with 1 success", result)
+	}
+
+	if !strings.HasSuffix(result.Name, "-"+defaultJobName) {
+		t.Errorf("Run: got job name %q, want it to end with -%s", result.Name, defaultJobName)
+	}
+
+	var job apiBatchv1.Job
+	if err := server.Get("jobs", "default", result.Name, &job); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if job.Labels[createdByLabel] != createdByValue {
+		t.Errorf("got job labels %v, want %s=%s", job.Labels, createdByLabel, createdByValue)
+	}
+
+	list, err := jobs.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != result.Name {
+		t.Errorf("List: got %d jobs, want only %s", len(list), result.Name)
+	}
+
+	if err := jobs.Delete(result.Name); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if list, err := jobs.List(ListOptions{}); err != nil || len(list) != 0 {
+		t.Errorf("List after Delete: got %d jobs and error %v, want none", len(list), err)
+	}
+}
+
+func TestGetJobOutput(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	jobs := NewJobOrchestrator(server.ClientSet(), "default")
+
+	if _, err := jobs.getJobOutput("report"); !IsNotFound(err) {
+		t.Errorf("getJobOutput of a job without pods: got %v, want a NotFound error", err)
+	}
+
+	// Added out of order, the output must still list the attempts by
+	// start time.
+	started := time.Now().Add(-time.Hour)
+	for _, pod := range []*apiv1.Pod{
+		jobPod("report", "report-b", apiv1.PodSucceeded, 0, started.Add(time.Minute)),
+		jobPod("report", "report-a", apiv1.PodFailed, 1, started),
+		jobPod("other", "other-a", apiv1.PodSucceeded, 0, started),
+	} {
+		if err := server.Add("pods", pod); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	server.SetLogs("default", "report-a", defaultJobName, "connection refused\n")
+	server.SetLogs("default", "report-b", defaultJobName, "42 rows")
+	server.SetLogs("default", "other-a", defaultJobName, "not this one")
+
+	output, err := jobs.getJobOutput("report")
+	if err != nil {
+		t.Fatalf("getJobOutput: %v", err)
+	}
+
+	want := "=== attempt 1: pod report-a (Failed) ===\n" +
+		"--- job-example ---\n" +
+		"connection refused\n" +
+		"=== attempt 2: pod report-b (Succeeded) ===\n" +
+		"--- job-example ---\n" +
+		"42 rows\n"
+	if output != want {
+		t.Errorf("getJobOutput: got\n%s\nwant\n%s", output, want)
+	}
+}
+
+func TestJobPrune(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	createdByRun := map[string]string{createdByLabel: createdByValue}
+	for _, job := range []*apiBatchv1.Job{
+		finishedJob("old", createdByRun, time.Now().Add(-2*time.Hour)),
+		finishedJob("recent", createdByRun, time.Now()),
+		finishedJob("someone-elses", nil, time.Now().Add(-2*time.Hour)),
+	} {
+		if err := server.Add("jobs", job); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	jobs := NewJobOrchestrator(server.ClientSet(), "default")
+
+	pruned, err := jobs.Prune(PruneOptions{OlderThan: time.Hour})
+	if err != nil {
+		t.Fatalf("Prune: %v", err)
+	}
+
+	if len(pruned) != 1 || pruned[0].Name != "old" {
+		t.Errorf("Prune: got %d jobs pruned, want only old", len(pruned))
+	}
+
+	list, err := jobs.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	left := []string{}
+	for _, job := range list {
+		left = append(left, job.Name)
+	}
+	if strings.Join(left, ",") != "recent,someone-elses" {
+		t.Errorf("List after Prune: got jobs %v, want recent and someone-elses", left)
+	}
+}
+
+func TestJobRunInvalidName(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	jobs := NewJobOrchestrator(server.ClientSet(), "default")
+
+	for _, name := range []string{
+		strings.Repeat("a", maxJobNameLength+1),
+		"Migrate",
+		"migrate_db",
+		"migrate.db",
+		"migrate-",
+	} {
+		if _, err := jobs.Run(JobSpec{Name: name}); !IsInvalid(err) {
+			t.Errorf("Run of a job named %q: got %v, want an Invalid error", name, err)
+		}
+	}
+
+	if list, err := jobs.List(ListOptions{}); err != nil || len(list) != 0 {
+		t.Errorf("List: got %d jobs and error %v, want none", len(list), err)
+	}
+}
@@ -0,0 +1,188 @@
+package orchestrator
+
+import (
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+	apiBatchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	defaultJobName  = "job-example"
+	defaultJobImage = "ubuntu:latest"
+)
+
+var defaultJobCommand = []string{"echo", "Hello World!"}
+
+// maxJobNameLength is the longest Name that fits, after the ULID and dash
+// Run prefixes it with, in the 63 characters of the job-name label the
+// job controller sets on the pods.
+const maxJobNameLength = 63 - 27
+
+// JobSpec describes the job JobOrchestrator.Run creates. It can be loaded
+// from a YAML file with LoadJobSpec:
+//
+//	name: migrate-db
+//	image: migrate/migrate:v3.0.0
+//	command: ["migrate"]
+//	args: ["-path", "/migrations", "up"]
+//	env:
+//	  DATABASE_URL: postgres://db/app
+//	resources:
+//	  requests:
+//	    cpu: 100m
+//	    memory: 64Mi
+//	  limits:
+//	    memory: 128Mi
+//	parallelism: 1
+//	completions: 1
+//	backoffLimit: 2
+//	activeDeadlineSeconds: 600
+//
+// An empty spec runs the original hello world example.
+type JobSpec struct {
+	// Name is the base name of the job, a DNS-1123 label: lowercase
+	// letters, digits and dashes. Each run prefixes it with a ULID, so
+	// runs never collide, which leaves room for 36 characters.
+	Name string `yaml:"name"`
+
+	Image     string             `yaml:"image"`
+	Command   []string           `yaml:"command"`
+	Args      []string           `yaml:"args"`
+	Env       map[string]string  `yaml:"env"`
+	Resources ContainerResources `yaml:"resources"`
+
+	Parallelism           *int32 `yaml:"parallelism"`
+	Completions           *int32 `yaml:"completions"`
+	BackoffLimit          *int32 `yaml:"backoffLimit"`
+	ActiveDeadlineSeconds *int64 `yaml:"activeDeadlineSeconds"`
+}
+
+// ContainerResources holds the compute resources of a job or deployment
+// container, as quantities such as "100m" or "64Mi" keyed by resource name.
+type ContainerResources struct {
+	Requests map[string]string `yaml:"requests"`
+	Limits   map[string]string `yaml:"limits"`
+}
+
+// LoadJobSpec reads a JobSpec from a YAML file.
+func LoadJobSpec(path string) (JobSpec, error) {
+	spec := JobSpec{}
+
+	content, err := ioutil.ReadFile(path)
+	if err != nil {
+		return spec, wrapError("read", "job spec", path, err)
+	}
+
+	if err := yaml.UnmarshalStrict(content, &spec); err != nil {
+		return spec, wrapError("decode", "job spec", path, err)
+	}
+
+	return spec, nil
+}
+
+func (s JobSpec) withDefaults() JobSpec {
+	if s.Name == "" {
+		s.Name = defaultJobName
+	}
+
+	if s.Image == "" {
+		s.Image = defaultJobImage
+		if len(s.Command) == 0 && len(s.Args) == 0 {
+			s.Command = defaultJobCommand
+		}
+	}
+
+	return s
+}
+
+// validate rejects a Name that is not a DNS-1123 label, which the server
+// refuses as a container name, or too long for the job-name label, which
+// it would only refuse once the job controller creates the pods.
+func (s JobSpec) validate() error {
+	if problems := validation.IsDNS1123Label(s.Name); len(problems) > 0 {
+		return fmt.Errorf("name %q is invalid: %s", s.Name, strings.Join(problems, "; "))
+	}
+
+	if len(s.Name) > maxJobNameLength {
+		return fmt.Errorf("name %q is %d characters long, at most %d fit next to the ULID prefix", s.Name, len(s.Name), maxJobNameLength)
+	}
+
+	return nil
+}
+
+// template builds the batch JobSpec of a job running the spec. Its pods
+// are labelled with the job's base name.
+func (s JobSpec) template() (apiBatchv1.JobSpec, error) {
+	s = s.withDefaults()
+
+	resources, err := s.Resources.requirements()
+	if err != nil {
+		return apiBatchv1.JobSpec{}, err
+	}
+
+	return apiBatchv1.JobSpec{
+		Parallelism:           s.Parallelism,
+		Completions:           s.Completions,
+		BackoffLimit:          s.BackoffLimit,
+		ActiveDeadlineSeconds: s.ActiveDeadlineSeconds,
+		Template: apiv1.PodTemplateSpec{
+			ObjectMeta: metav1.ObjectMeta{
+				Labels: map[string]string{
+					"app": s.Name,
+				},
+			},
+			Spec: apiv1.PodSpec{
+				Containers: []apiv1.Container{
+					{
+						Name:      s.Name,
+						Image:     s.Image,
+						Command:   s.Command,
+						Args:      s.Args,
+						Env:       envVars(s.Env),
+						Resources: resources,
+					},
+				},
+				RestartPolicy: apiv1.RestartPolicyNever,
+			},
+		},
+	}, nil
+}
+
+func (r ContainerResources) requirements() (apiv1.ResourceRequirements, error) {
+	requests, err := resourceList(r.Requests)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, err
+	}
+
+	limits, err := resourceList(r.Limits)
+	if err != nil {
+		return apiv1.ResourceRequirements{}, err
+	}
+
+	return apiv1.ResourceRequirements{Requests: requests, Limits: limits}, nil
+}
+
+func resourceList(quantities map[string]string) (apiv1.ResourceList, error) {
+	if len(quantities) == 0 {
+		return nil, nil
+	}
+
+	list := apiv1.ResourceList{}
+	for name, value := range quantities {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, err
+		}
+
+		list[apiv1.ResourceName(name)] = quantity
+	}
+
+	return list, nil
+}
@@ -0,0 +1,223 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	apiBatchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const jobTimeout = time.Hour
+
+// JobResult describes how a finished job ended, or how far a running one
+// got.
+type JobResult struct {
+	Name      string
+	Active    int32
+	Succeeded int32
+	Failed    int32
+
+	// Finished is false while the job is still running, Complete and the
+	// failure reason are then unset.
+	Finished bool
+
+	// Complete is true when the job succeeded and false when it failed.
+	Complete bool
+
+	// Reason and Message explain why a job failed, e.g.
+	// BackoffLimitExceeded or DeadlineExceeded.
+	Reason  string
+	Message string
+
+	// Containers lists every terminated container run of the job's pods,
+	// oldest pod first.
+	Containers []ContainerExit
+}
+
+// ContainerExit is the final state of a single container run.
+type ContainerExit struct {
+	Pod       string
+	Container string
+	ExitCode  int32
+	Reason    string
+}
+
+// jobResult returns the result of a job that has a Complete or Failed
+// condition, or false while the job is still running.
+func jobResult(job *apiBatchv1.Job) (*JobResult, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != apiv1.ConditionTrue {
+			continue
+		}
+
+		switch condition.Type {
+		case apiBatchv1.JobComplete, apiBatchv1.JobFailed:
+			return &JobResult{
+				Name:      job.Name,
+				Active:    job.Status.Active,
+				Succeeded: job.Status.Succeeded,
+				Failed:    job.Status.Failed,
+				Finished:  true,
+				Complete:  condition.Type == apiBatchv1.JobComplete,
+				Reason:    condition.Reason,
+				Message:   condition.Message,
+			}, true
+		}
+	}
+
+	return nil, false
+}
+
+// Status prints and returns the state of a job by its full name, as printed
+// by Run, along with the exit codes of its terminated containers.
+func (j JobOrchestrator) Status(jobName string) (*JobResult, error) {
+	job, err := j.KubernetesClientSet.BatchV1().Jobs(j.Namespace).Get(jobName, metav1.GetOptions{})
+	if err != nil {
+		return nil, wrapError("get status of", "job", jobName, err)
+	}
+
+	result, finished := jobResult(job)
+	if !finished {
+		result = &JobResult{
+			Name:      job.Name,
+			Active:    job.Status.Active,
+			Succeeded: job.Status.Succeeded,
+			Failed:    job.Status.Failed,
+		}
+	}
+
+	if result.Containers, err = j.containerExits(jobName); err != nil {
+		return nil, wrapError("get status of", "job", jobName, err)
+	}
+
+	printJobResult(result)
+	return result, nil
+}
+
+// waitForJob watches the job until it is complete or failed, the
+// orchestrator's Timeout expires or Interrupt is closed. The watch is resumed from the last seen
+// resourceVersion whenever the server closes it.
+func (j JobOrchestrator) waitForJob(jobName string) (*JobResult, error) {
+	jobInterface := j.KubernetesClientSet.BatchV1().Jobs(j.Namespace)
+	job, err := jobInterface.Get(jobName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	timeout := j.Timeout
+	if timeout <= 0 {
+		timeout = jobTimeout
+	}
+
+	deadline := time.After(timeout)
+	resourceVersion := job.ResourceVersion
+	result, finished := jobResult(job)
+
+	for !finished {
+		watcher, err := jobInterface.Watch(metav1.ListOptions{
+			FieldSelector:   "metadata.name=" + jobName,
+			ResourceVersion: resourceVersion,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		result, finished, err = watchJob(watcher, deadline, j.Interrupt, jobName, &resourceVersion)
+		watcher.Stop()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	result.Containers, err = j.containerExits(jobName)
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// watchJob consumes a single watch stream. It returns false without error
+// when the stream ended and the caller should watch again.
+func watchJob(watcher watch.Interface, deadline <-chan time.Time, interrupt <-chan struct{}, jobName string, resourceVersion *string) (*JobResult, bool, error) {
+	for {
+		select {
+		case <-deadline:
+			return nil, false, newError("wait for", "job", jobName, ReasonTimeout, errors.New("timed out"))
+		case <-interrupt:
+			return nil, false, newError("wait for", "job", jobName, ReasonInterrupted, errors.New("interrupted"))
+		case event, open := <-watcher.ResultChan():
+			if !open {
+				return nil, false, nil
+			}
+
+			switch event.Type {
+			case watch.Error:
+				err := apierrors.FromObject(event.Object)
+				if status, ok := err.(apierrors.APIStatus); ok && status.Status().Code == http.StatusGone {
+					// The resourceVersion is too old: start over from the current state.
+					*resourceVersion = ""
+					return nil, false, nil
+				}
+				return nil, false, err
+			case watch.Deleted:
+				return nil, false, newError("wait for", "job", jobName, ReasonNotFound, errors.New("job was deleted"))
+			}
+
+			job, parsed := event.Object.(*apiBatchv1.Job)
+			if !parsed {
+				continue
+			}
+
+			*resourceVersion = job.ResourceVersion
+			fmt.Printf("Job %s: %d active, %d succeeded, %d failed\n", jobName, job.Status.Active, job.Status.Succeeded, job.Status.Failed)
+			if result, finished := jobResult(job); finished {
+				return result, true, nil
+			}
+		}
+	}
+}
+
+// containerExits collects the terminated container runs of the job's pods,
+// previous runs of restarted containers included.
+func (j JobOrchestrator) containerExits(jobName string) ([]ContainerExit, error) {
+	podList, err := j.KubernetesClientSet.CoreV1().Pods(j.Namespace).List(metav1.ListOptions{
+		LabelSelector: "job-name=" + jobName,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	pods := podList.Items
+	sort.Slice(pods, func(a, b int) bool {
+		return podStartTime(pods[a]).Before(podStartTime(pods[b]))
+	})
+
+	var exits []ContainerExit
+	for _, pod := range pods {
+		statuses := podContainerStatuses(pod.Status)
+		for _, status := range statuses {
+			for _, terminated := range []*apiv1.ContainerStateTerminated{status.LastTerminationState.Terminated, status.State.Terminated} {
+				if terminated == nil {
+					continue
+				}
+
+				exits = append(exits, ContainerExit{
+					Pod:       pod.Name,
+					Container: status.Name,
+					ExitCode:  terminated.ExitCode,
+					Reason:    terminated.Reason,
+				})
+			}
+		}
+	}
+
+	return exits, nil
+}
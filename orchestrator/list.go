@@ -0,0 +1,50 @@
+package orchestrator
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListOptions narrows down the objects returned by the List methods.
+type ListOptions struct {
+	// AllNamespaces lists objects across every namespace instead of the
+	// orchestrator's one.
+	AllNamespaces bool
+
+	// LabelSelector and FieldSelector take the API server syntax, e.g.
+	// "app=web,tier!=cache" and "status.phase=Running".
+	LabelSelector string
+	FieldSelector string
+
+	// Limit fetches the objects in pages of at most this many, so large
+	// namespaces do not have to be sent in a single response. List still
+	// returns every object. Zero fetches them all at once.
+	Limit int64
+}
+
+// listNamespace returns the namespace a List call should query.
+func listNamespace(namespace string, allNamespaces bool) string {
+	if allNamespaces {
+		return metav1.NamespaceAll
+	}
+
+	return namespace
+}
+
+// listPages calls listPage for every page of results, passing the continue
+// token returned by the previous page, until the last page.
+func listPages(options ListOptions, listPage func(metav1.ListOptions) (string, error)) error {
+	apiOptions := metav1.ListOptions{
+		LabelSelector: options.LabelSelector,
+		FieldSelector: options.FieldSelector,
+		Limit:         options.Limit,
+	}
+
+	for {
+		continueToken, err := listPage(apiOptions)
+		if err != nil || continueToken == "" {
+			return err
+		}
+
+		apiOptions.Continue = continueToken
+	}
+}
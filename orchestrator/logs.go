@@ -0,0 +1,264 @@
+package orchestrator
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// LogOptions selects which log lines of a job or pod are shown.
+type LogOptions struct {
+	// Follow streams the logs while the job runs, instead of fetching them
+	// once it has finished. Each line is prefixed with its pod and container.
+	Follow bool
+
+	// Since only shows lines newer than this duration. Zero shows all.
+	Since time.Duration
+
+	// Tail only shows this many of the most recent lines. Zero shows all.
+	Tail int64
+
+	Timestamps bool
+
+	// Previous shows the logs of the previous run of a restarted container
+	// instead. It only applies to pod logs; job logs show both runs.
+	Previous bool
+}
+
+func (o LogOptions) podLogOptions(container string) *apiv1.PodLogOptions {
+	podLogOptions := &apiv1.PodLogOptions{
+		Container:  container,
+		Follow:     o.Follow,
+		Timestamps: o.Timestamps,
+	}
+
+	if o.Since > 0 {
+		sinceSeconds := int64(o.Since.Seconds())
+		podLogOptions.SinceSeconds = &sinceSeconds
+	}
+
+	if o.Tail > 0 {
+		tail := o.Tail
+		podLogOptions.TailLines = &tail
+	}
+
+	return podLogOptions
+}
+
+// followLogs streams the logs of every container of the job's pods as
+// soon as it starts running, init containers included, until stop is
+// closed and the streams end, or Interrupt is closed.
+// The returned channel is closed once all streams have ended.
+func (j JobOrchestrator) followLogs(jobName string, stop <-chan struct{}) <-chan struct{} {
+	done := make(chan struct{})
+	selector := metav1.ListOptions{LabelSelector: "job-name=" + jobName}
+	podInterface := j.KubernetesClientSet.CoreV1().Pods(j.Namespace)
+
+	var printing sync.Mutex
+	var streams sync.WaitGroup
+	started := map[string]bool{}
+
+	follow := func(pod *apiv1.Pod) {
+		statuses := podContainerStatuses(pod.Status)
+		for _, container := range podContainers(pod.Spec) {
+			prefix := fmt.Sprintf("[%s/%s]", pod.Name, container.Name)
+			if started[prefix] || !containerStarted(findContainerStatus(statuses, container.Name)) {
+				continue
+			}
+			started[prefix] = true
+
+			streams.Add(1)
+			go func(podName, containerName, prefix string) {
+				defer streams.Done()
+				j.streamLogs(podName, containerName, func(line string) {
+					printing.Lock()
+					defer printing.Unlock()
+					fmt.Println(prefix, line)
+				})
+			}(pod.Name, container.Name, prefix)
+		}
+	}
+
+	go func() {
+		defer close(done)
+		defer streams.Wait()
+
+		watcher, err := podInterface.Watch(selector)
+		if err != nil {
+			fmt.Println("Error on watch pods. Error: ", err.Error())
+			return
+		}
+		defer watcher.Stop()
+
+	watching:
+		for {
+			select {
+			case event, open := <-watcher.ResultChan():
+				if !open {
+					<-stop
+					break watching
+				}
+
+				if pod, parsed := event.Object.(*apiv1.Pod); parsed {
+					follow(pod)
+				}
+			case <-stop:
+				break watching
+			}
+		}
+
+		select {
+		case <-j.Interrupt:
+			return
+		default:
+		}
+
+		// The job is over: catch up with pods the watch did not report yet.
+		podList, err := podInterface.List(selector)
+		if err != nil {
+			fmt.Println("Error on get pods. Error: ", err.Error())
+			return
+		}
+
+		for i := range podList.Items {
+			follow(&podList.Items[i])
+		}
+	}()
+
+	return done
+}
+
+// streamLogs follows the logs of a container until it terminates or
+// Interrupt is closed.
+func (j JobOrchestrator) streamLogs(podName, containerName string, printLine func(string)) {
+	podLogOptions := j.Logs.podLogOptions(containerName)
+	podLogOptions.Follow = true
+
+	stream, err := j.KubernetesClientSet.CoreV1().Pods(j.Namespace).GetLogs(podName, podLogOptions).Stream()
+	if err != nil {
+		printLine("error on stream logs: " + err.Error())
+		return
+	}
+	defer stream.Close()
+
+	// Closing the stream on interrupt unblocks the scanner.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-j.Interrupt:
+			stream.Close()
+		case <-finished:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		printLine(scanner.Text())
+	}
+}
+
+// getJobOutput gathers the logs of every pod the job created, retries
+// included, ordered by start time. Each container's logs, init containers
+// first, follow a header; restarted containers also show the logs of
+// their previous run.
+func (j JobOrchestrator) getJobOutput(jobName string) (string, error) {
+	podInterface := j.KubernetesClientSet.CoreV1().Pods(j.Namespace)
+	podList, err := podInterface.List(metav1.ListOptions{
+		LabelSelector: "job-name=" + jobName,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	if len(podList.Items) == 0 {
+		return "", newError("get output of", "job", jobName, ReasonNotFound, errors.New("pod not found"))
+	}
+
+	pods := podList.Items
+	sort.Slice(pods, func(a, b int) bool {
+		return podStartTime(pods[a]).Before(podStartTime(pods[b]))
+	})
+
+	var output bytes.Buffer
+	for attempt, pod := range pods {
+		fmt.Fprintf(&output, "=== attempt %d: pod %s (%s) ===\n", attempt+1, pod.Name, pod.Status.Phase)
+
+		statuses := podContainerStatuses(pod.Status)
+		for _, container := range podContainers(pod.Spec) {
+			status := findContainerStatus(statuses, container.Name)
+			if status != nil && status.RestartCount > 0 {
+				fmt.Fprintf(&output, "--- %s (previous run) ---\n", container.Name)
+				output.WriteString(j.containerLogs(pod.Name, container.Name, true))
+			}
+
+			fmt.Fprintf(&output, "--- %s ---\n", container.Name)
+			output.WriteString(j.containerLogs(pod.Name, container.Name, false))
+		}
+	}
+
+	return output.String(), nil
+}
+
+// containerLogs fetches the logs of a container. Failures, such as a
+// container that never started, are reported in place of the logs so the
+// output of the other containers is not lost.
+func (j JobOrchestrator) containerLogs(podName, containerName string, previous bool) string {
+	podLogOptions := j.Logs.podLogOptions(containerName)
+	podLogOptions.Follow = false
+	podLogOptions.Previous = previous
+
+	logs, err := j.KubernetesClientSet.CoreV1().Pods(j.Namespace).GetLogs(podName, podLogOptions).DoRaw()
+	if err != nil {
+		return fmt.Sprintf("(no logs: %s)\n", err.Error())
+	}
+
+	if len(logs) > 0 && logs[len(logs)-1] != '\n' {
+		logs = append(logs, '\n')
+	}
+
+	return string(logs)
+}
+
+func podStartTime(pod apiv1.Pod) time.Time {
+	if pod.Status.StartTime != nil {
+		return pod.Status.StartTime.Time
+	}
+
+	return pod.CreationTimestamp.Time
+}
+
+// podContainers lists the init containers of a pod followed by its
+// regular containers.
+func podContainers(podSpec apiv1.PodSpec) []apiv1.Container {
+	return append(append([]apiv1.Container{}, podSpec.InitContainers...), podSpec.Containers...)
+}
+
+// podContainerStatuses lists the statuses of the init containers of a pod
+// followed by those of its regular containers.
+func podContainerStatuses(podStatus apiv1.PodStatus) []apiv1.ContainerStatus {
+	return append(append([]apiv1.ContainerStatus{}, podStatus.InitContainerStatuses...), podStatus.ContainerStatuses...)
+}
+
+// containerStarted reports whether the container is running or has run,
+// so it has logs to stream.
+func containerStarted(status *apiv1.ContainerStatus) bool {
+	return status != nil && (status.State.Running != nil || status.State.Terminated != nil)
+}
+
+func findContainerStatus(statuses []apiv1.ContainerStatus, name string) *apiv1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,715 @@
+package orchestratortest
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+// resourceKind describes a resource served by the APIServer.
+type resourceKind struct {
+	kind       string
+	namespaced bool
+}
+
+var resourceKinds = map[string]resourceKind{
+	"deployments":              {kind: "Deployment", namespaced: true},
+	"horizontalpodautoscalers": {kind: "HorizontalPodAutoscaler", namespaced: true},
+	"replicasets":              {kind: "ReplicaSet", namespaced: true},
+	"services":                 {kind: "Service", namespaced: true},
+	"jobs":                     {kind: "Job", namespaced: true},
+	"cronjobs":                 {kind: "CronJob", namespaced: true},
+	"pods":                     {kind: "Pod", namespaced: true},
+	"configmaps":               {kind: "ConfigMap", namespaced: true},
+	"secrets":                  {kind: "Secret", namespaced: true},
+	"events":                   {kind: "Event", namespaced: true},
+	"namespaces":               {kind: "Namespace", namespaced: false},
+}
+
+// Object is the generic JSON form in which the APIServer stores objects.
+type Object map[string]interface{}
+
+// Reactor is called with every object created for a resource, after it has
+// been stored. It runs outside the server lock, so it may call back into the
+// server.
+type Reactor func(object Object)
+
+type event struct {
+	eventType       string
+	resource        string
+	resourceVersion int64
+	object          Object
+}
+
+// APIServer is an in-memory Kubernetes API server listening on a local
+// port. Create one with NewAPIServer and Close it when done.
+type APIServer struct {
+	*httptest.Server
+
+	mu              sync.Mutex
+	resourceVersion int64
+	objects         map[string]Object
+	events          []event
+	logs            map[string]string
+	reactors        map[string][]Reactor
+	watchers        map[chan struct{}]bool
+	groupVersions   []string
+	closed          chan struct{}
+}
+
+// NewAPIServer starts an empty APIServer.
+func NewAPIServer() *APIServer {
+	s := &APIServer{
+		objects:       map[string]Object{},
+		logs:          map[string]string{},
+		reactors:      map[string][]Reactor{},
+		watchers:      map[chan struct{}]bool{},
+		groupVersions: append([]string{}, DefaultGroupVersions...),
+		closed:        make(chan struct{}),
+	}
+
+	s.Server = httptest.NewServer(http.HandlerFunc(s.serveHTTP))
+	return s
+}
+
+// Close ends the open watch streams and shuts the server down.
+func (s *APIServer) Close() {
+	close(s.closed)
+	s.Server.Close()
+}
+
+// ClientSet returns a client set talking to the server.
+func (s *APIServer) ClientSet() kubernetes.Interface {
+	return kubernetes.NewForConfigOrDie(&rest.Config{Host: s.URL})
+}
+
+// Add stores an object, typically a typed API object such as *apiv1.Pod,
+// as if it had been created through the API.
+func (s *APIServer) Add(resource string, object interface{}) error {
+	generic, err := toObject(object)
+	if err != nil {
+		return err
+	}
+
+	_, status := s.create(resource, generic.namespace(), generic)
+	if status != nil {
+		return fmt.Errorf("%s", status.message)
+	}
+
+	return nil
+}
+
+// Modify changes a stored object in place and notifies watchers, the way
+// a controller updating the object's status would.
+func (s *APIServer) Modify(resource, namespace, name string, modify func(object Object)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stored, found := s.objects[objectKey(resource, namespace, name)]
+	if !found {
+		return fmt.Errorf("%s %s/%s not found", resource, namespace, name)
+	}
+
+	modify(stored)
+	s.resourceVersion++
+	stored.metadata()["resourceVersion"] = strconv.FormatInt(s.resourceVersion, 10)
+	s.record("MODIFIED", resource, stored)
+	return nil
+}
+
+// Get decodes a stored object into the given pointer, typically a typed
+// API object.
+func (s *APIServer) Get(resource, namespace, name string, into interface{}) error {
+	s.mu.Lock()
+	stored, found := s.objects[objectKey(resource, namespace, name)]
+	s.mu.Unlock()
+
+	if !found {
+		return fmt.Errorf("%s %s/%s not found", resource, namespace, name)
+	}
+
+	return convert(stored, into)
+}
+
+// SetLogs sets the log output served for a container of a pod.
+func (s *APIServer) SetLogs(namespace, pod, container, logs string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.logs[namespace+"/"+pod+"/"+container] = logs
+}
+
+// OnCreate registers a reactor for objects created for the resource.
+func (s *APIServer) OnCreate(resource string, reactor Reactor) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.reactors[resource] = append(s.reactors[resource], reactor)
+}
+
+// request is an API request path broken into its parts.
+type request struct {
+	groupVersion string
+	namespace    string
+	resource     string
+	name         string
+	subresource  string
+}
+
+func parseRequest(path string) (request, bool) {
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+
+	var req request
+	switch {
+	case len(segments) >= 2 && segments[0] == "api":
+		req.groupVersion = segments[1]
+		segments = segments[2:]
+	case len(segments) >= 3 && segments[0] == "apis":
+		req.groupVersion = segments[1] + "/" + segments[2]
+		segments = segments[3:]
+	default:
+		return req, false
+	}
+
+	if len(segments) >= 3 && segments[0] == "namespaces" {
+		req.namespace = segments[1]
+		segments = segments[2:]
+	}
+
+	if len(segments) == 0 || len(segments) > 3 {
+		return req, false
+	}
+
+	req.resource = segments[0]
+	if len(segments) > 1 {
+		req.name = segments[1]
+	}
+	if len(segments) > 2 {
+		req.subresource = segments[2]
+	}
+
+	_, known := resourceKinds[req.resource]
+	return req, known
+}
+
+func (s *APIServer) serveHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method == http.MethodGet && s.serveDiscovery(w, r.URL.Path) {
+		return
+	}
+
+	req, ok := parseRequest(r.URL.Path)
+	if !ok || !s.serves(req.groupVersion) {
+		writeStatus(w, newStatus(http.StatusNotFound, "NotFound", "", "", "the server could not find the requested resource"))
+		return
+	}
+
+	query := r.URL.Query()
+
+	switch {
+	case req.subresource == "log" && r.Method == http.MethodGet:
+		s.serveLogs(w, req, query.Get("container"))
+	case req.subresource != "":
+		writeStatus(w, newStatus(http.StatusNotFound, "NotFound", req.resource, req.name, "subresource not supported"))
+	case req.name == "" && r.Method == http.MethodGet && isTrue(query.Get("watch")):
+		s.serveWatch(w, r, req)
+	case req.name == "" && r.Method == http.MethodGet:
+		s.serveList(w, req, query)
+	case req.name == "" && r.Method == http.MethodPost:
+		s.serveCreate(w, r, req)
+	case r.Method == http.MethodGet:
+		s.serveGet(w, req)
+	case r.Method == http.MethodPut:
+		s.serveUpdate(w, r, req)
+	case r.Method == http.MethodPatch:
+		s.servePatch(w, r, req)
+	case r.Method == http.MethodDelete:
+		s.serveDelete(w, req)
+	default:
+		writeStatus(w, newStatus(http.StatusMethodNotAllowed, "MethodNotAllowed", req.resource, req.name, "method not allowed"))
+	}
+}
+
+// serveList lists the matching objects. With a limit, it returns them in
+// pages, the continue token being the offset of the next page.
+func (s *APIServer) serveList(w http.ResponseWriter, req request, query url.Values) {
+	labels, err := parseSelector(query.Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	fields, err := parseSelector(query.Get("fieldSelector"))
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	limit, offset, err := parsePage(query.Get("limit"), query.Get("continue"))
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	items := []interface{}{}
+	for _, key := range sortedKeys(s.objects) {
+		object := s.objects[key]
+		if object.resource() == req.resource && object.matches(req.namespace, labels, fields) {
+			items = append(items, withType(object, req))
+		}
+	}
+	resourceVersion := s.resourceVersion
+	s.mu.Unlock()
+
+	metadata := map[string]interface{}{
+		"resourceVersion": strconv.FormatInt(resourceVersion, 10),
+	}
+
+	if offset > len(items) {
+		offset = len(items)
+	}
+	items = items[offset:]
+	if limit > 0 && limit < len(items) {
+		items = items[:limit]
+		metadata["continue"] = strconv.Itoa(offset + limit)
+	}
+
+	writeJSON(w, http.StatusOK, map[string]interface{}{
+		"kind":       resourceKinds[req.resource].kind + "List",
+		"apiVersion": req.groupVersion,
+		"metadata":   metadata,
+		"items":      items,
+	})
+}
+
+func parsePage(limit, continueToken string) (int, int, error) {
+	var pageSize, offset int
+	var err error
+	if limit != "" {
+		if pageSize, err = strconv.Atoi(limit); err != nil {
+			return 0, 0, fmt.Errorf("invalid limit %q", limit)
+		}
+	}
+
+	if continueToken != "" {
+		if offset, err = strconv.Atoi(continueToken); err != nil || offset < 0 {
+			return 0, 0, fmt.Errorf("invalid continue token %q", continueToken)
+		}
+	}
+
+	return pageSize, offset, nil
+}
+
+func (s *APIServer) serveGet(w http.ResponseWriter, req request) {
+	s.mu.Lock()
+	object, found := s.objects[objectKey(req.resource, req.namespace, req.name)]
+	if found {
+		object = withType(object, req)
+	}
+	s.mu.Unlock()
+
+	if !found {
+		writeStatus(w, notFound(req))
+		return
+	}
+
+	writeJSON(w, http.StatusOK, object)
+}
+
+func (s *APIServer) serveCreate(w http.ResponseWriter, r *http.Request, req request) {
+	object, err := readObject(r)
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	created, status := s.create(req.resource, req.namespace, object)
+	if status != nil {
+		writeStatus(w, status)
+		return
+	}
+
+	writeJSON(w, http.StatusCreated, withType(created, req))
+}
+
+func (s *APIServer) serveUpdate(w http.ResponseWriter, r *http.Request, req request) {
+	object, err := readObject(r)
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, req.name, err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	key := objectKey(req.resource, req.namespace, req.name)
+	stored, found := s.objects[key]
+	if !found {
+		s.mu.Unlock()
+		writeStatus(w, notFound(req))
+		return
+	}
+
+	if version := object.metadata()["resourceVersion"]; version != nil && version != stored.metadata()["resourceVersion"] {
+		s.mu.Unlock()
+		writeStatus(w, newStatus(http.StatusConflict, "Conflict", req.resource, req.name,
+			"the object has been modified; please apply your changes to the latest version and try again"))
+		return
+	}
+
+	updated := s.replace(req.resource, stored, object)
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, withType(updated, req))
+}
+
+func (s *APIServer) servePatch(w http.ResponseWriter, r *http.Request, req request) {
+	contentType := r.Header.Get("Content-Type")
+	if contentType != "application/merge-patch+json" && contentType != "application/strategic-merge-patch+json" {
+		writeStatus(w, newStatus(http.StatusUnsupportedMediaType, "UnsupportedMediaType", req.resource, req.name,
+			"only merge and strategic merge patches are supported"))
+		return
+	}
+
+	patch, err := readObject(r)
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, req.name, err.Error()))
+		return
+	}
+
+	s.mu.Lock()
+	stored, found := s.objects[objectKey(req.resource, req.namespace, req.name)]
+	if !found {
+		s.mu.Unlock()
+		writeStatus(w, notFound(req))
+		return
+	}
+
+	patched := mergePatch(map[string]interface{}(stored.copy()), map[string]interface{}(patch)).(map[string]interface{})
+	updated := s.replace(req.resource, stored, Object(patched))
+	s.mu.Unlock()
+
+	writeJSON(w, http.StatusOK, withType(updated, req))
+}
+
+func (s *APIServer) serveDelete(w http.ResponseWriter, req request) {
+	s.mu.Lock()
+	key := objectKey(req.resource, req.namespace, req.name)
+	stored, found := s.objects[key]
+	if found {
+		delete(s.objects, key)
+		s.resourceVersion++
+		stored.metadata()["resourceVersion"] = strconv.FormatInt(s.resourceVersion, 10)
+		s.record("DELETED", req.resource, stored)
+	}
+	s.mu.Unlock()
+
+	if !found {
+		writeStatus(w, notFound(req))
+		return
+	}
+
+	writeStatus(w, newStatus(http.StatusOK, "", req.resource, req.name, ""))
+}
+
+func (s *APIServer) serveLogs(w http.ResponseWriter, req request, container string) {
+	s.mu.Lock()
+	pod, found := s.objects[objectKey("pods", req.namespace, req.name)]
+	if found && container == "" {
+		container = pod.firstContainer()
+	}
+	logs := s.logs[req.namespace+"/"+req.name+"/"+container]
+	s.mu.Unlock()
+
+	if !found {
+		writeStatus(w, notFound(req))
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/plain")
+	w.WriteHeader(http.StatusOK)
+	w.Write([]byte(logs))
+}
+
+// serveWatch streams watch events. Without a resourceVersion the stream
+// starts with an ADDED event per existing object; with one it replays the
+// changes made after that version.
+func (s *APIServer) serveWatch(w http.ResponseWriter, r *http.Request, req request) {
+	query := r.URL.Query()
+
+	labels, err := parseSelector(query.Get("labelSelector"))
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	fields, err := parseSelector(query.Get("fieldSelector"))
+	if err != nil {
+		writeStatus(w, newStatus(http.StatusBadRequest, "BadRequest", req.resource, "", err.Error()))
+		return
+	}
+
+	var timeout <-chan time.Time
+	if seconds, err := strconv.Atoi(query.Get("timeoutSeconds")); err == nil && seconds > 0 {
+		timeout = time.After(time.Duration(seconds) * time.Second)
+	}
+
+	wake := make(chan struct{}, 1)
+
+	s.mu.Lock()
+	s.watchers[wake] = true
+
+	pending := []event{}
+	cursor := len(s.events)
+	if since, err := strconv.ParseInt(query.Get("resourceVersion"), 10, 64); err == nil && since > 0 {
+		for i, recorded := range s.events {
+			if recorded.resourceVersion > since {
+				cursor = i
+				break
+			}
+		}
+		pending = append(pending, s.events[cursor:]...)
+		cursor = len(s.events)
+	} else {
+		for _, key := range sortedKeys(s.objects) {
+			object := s.objects[key]
+			if object.resource() == req.resource {
+				pending = append(pending, event{eventType: "ADDED", resource: req.resource, object: object.copy()})
+			}
+		}
+	}
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		delete(s.watchers, wake)
+		s.mu.Unlock()
+	}()
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	encoder := json.NewEncoder(w)
+
+	for {
+		for _, pendingEvent := range pending {
+			if pendingEvent.resource != req.resource || !pendingEvent.object.matches(req.namespace, labels, fields) {
+				continue
+			}
+
+			encoder.Encode(map[string]interface{}{
+				"type":   pendingEvent.eventType,
+				"object": withType(pendingEvent.object, req),
+			})
+		}
+		if flusher != nil {
+			flusher.Flush()
+		}
+
+		select {
+		case <-wake:
+		case <-timeout:
+			return
+		case <-r.Context().Done():
+			return
+		case <-s.closed:
+			return
+		}
+
+		s.mu.Lock()
+		pending = append([]event{}, s.events[cursor:]...)
+		cursor = len(s.events)
+		s.mu.Unlock()
+	}
+}
+
+// create stores a new object and runs the reactors of its resource.
+func (s *APIServer) create(resource, namespace string, object Object) (Object, *status) {
+	s.mu.Lock()
+
+	metadata := object.metadata()
+	name, _ := metadata["name"].(string)
+	if name == "" {
+		if generateName, _ := metadata["generateName"].(string); generateName != "" {
+			name = fmt.Sprintf("%s%05d", generateName, s.resourceVersion+1)
+			metadata["name"] = name
+		}
+	}
+
+	if name == "" {
+		s.mu.Unlock()
+		return nil, newStatus(http.StatusUnprocessableEntity, "Invalid", resource, "", "name or generateName is required")
+	}
+
+	if !resourceKinds[resource].namespaced {
+		namespace = ""
+	}
+
+	key := objectKey(resource, namespace, name)
+	if _, exists := s.objects[key]; exists {
+		s.mu.Unlock()
+		return nil, newStatus(http.StatusConflict, "AlreadyExists", resource, name,
+			fmt.Sprintf("%s %q already exists", resource, name))
+	}
+
+	s.resourceVersion++
+	if namespace != "" {
+		metadata["namespace"] = namespace
+	}
+	metadata["uid"] = fmt.Sprintf("uid-%d", s.resourceVersion)
+	metadata["resourceVersion"] = strconv.FormatInt(s.resourceVersion, 10)
+	metadata["creationTimestamp"] = time.Now().UTC().Format(time.RFC3339)
+	metadata["generation"] = 1
+	object[resourceField] = resource
+
+	if resource == "jobs" {
+		defaultJob(object)
+	}
+
+	s.objects[key] = object
+	s.record("ADDED", resource, object)
+
+	created := object.copy()
+	reactors := append([]Reactor{}, s.reactors[resource]...)
+	s.mu.Unlock()
+
+	for _, reactor := range reactors {
+		reactor(created.copy())
+	}
+
+	return created, nil
+}
+
+// replace swaps the stored object for its new version, keeping the
+// server-owned metadata. It must be called with the lock held.
+func (s *APIServer) replace(resource string, stored, object Object) Object {
+	metadata := object.metadata()
+	storedMetadata := stored.metadata()
+	for _, field := range []string{"name", "namespace", "uid", "creationTimestamp", "generation"} {
+		if value, found := storedMetadata[field]; found {
+			metadata[field] = value
+		}
+	}
+
+	if specJSON(stored) != specJSON(object) {
+		metadata["generation"] = toInt(storedMetadata["generation"]) + 1
+	}
+
+	s.resourceVersion++
+	metadata["resourceVersion"] = strconv.FormatInt(s.resourceVersion, 10)
+	object[resourceField] = resource
+
+	s.objects[objectKey(resource, object.namespace(), object.name())] = object
+	s.record("MODIFIED", resource, object)
+	return object.copy()
+}
+
+// record appends an event and wakes the watchers. It must be called with
+// the lock held.
+func (s *APIServer) record(eventType, resource string, object Object) {
+	version, _ := strconv.ParseInt(object.metadata()["resourceVersion"].(string), 10, 64)
+	s.events = append(s.events, event{
+		eventType:       eventType,
+		resource:        resource,
+		resourceVersion: version,
+		object:          object.copy(),
+	})
+
+	for wake := range s.watchers {
+		select {
+		case wake <- struct{}{}:
+		default:
+		}
+	}
+}
+
+// defaultJob mimics the API server defaults that tie a job to its pods.
+func defaultJob(job Object) {
+	spec, _ := job["spec"].(map[string]interface{})
+	if spec == nil {
+		return
+	}
+
+	template, _ := spec["template"].(map[string]interface{})
+	if template == nil {
+		template = map[string]interface{}{}
+		spec["template"] = template
+	}
+
+	templateMetadata, _ := template["metadata"].(map[string]interface{})
+	if templateMetadata == nil {
+		templateMetadata = map[string]interface{}{}
+		template["metadata"] = templateMetadata
+	}
+
+	labels, _ := templateMetadata["labels"].(map[string]interface{})
+	if labels == nil {
+		labels = map[string]interface{}{}
+		templateMetadata["labels"] = labels
+	}
+
+	if manual, _ := spec["manualSelector"].(bool); !manual {
+		labels["controller-uid"] = job.metadata()["uid"]
+		labels["job-name"] = job.name()
+		spec["selector"] = map[string]interface{}{
+			"matchLabels": map[string]interface{}{"controller-uid": job.metadata()["uid"]},
+		}
+	}
+
+	if _, found := job.metadata()["labels"]; !found {
+		jobLabels := map[string]interface{}{}
+		for key, value := range labels {
+			jobLabels[key] = value
+		}
+		job.metadata()["labels"] = jobLabels
+	}
+}
+
+func readObject(r *http.Request) (Object, error) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	object := Object{}
+	if err := json.Unmarshal(body, &object); err != nil {
+		return nil, err
+	}
+
+	if object.metadata() == nil {
+		object["metadata"] = map[string]interface{}{}
+	}
+
+	return object, nil
+}
+
+func writeJSON(w http.ResponseWriter, code int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(body)
+}
+
+func isTrue(value string) bool {
+	return value == "true" || value == "1"
+}
+
+func toInt(value interface{}) int64 {
+	switch number := value.(type) {
+	case float64:
+		return int64(number)
+	case int:
+		return int64(number)
+	case int64:
+		return number
+	}
+
+	return 0
+}
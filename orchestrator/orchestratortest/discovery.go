@@ -0,0 +1,109 @@
+package orchestratortest
+
+import (
+	"net/http"
+	"runtime"
+	"strings"
+)
+
+// DefaultGroupVersions are the API group versions an APIServer serves
+// unless told otherwise with SetGroupVersions.
+var DefaultGroupVersions = []string{
+	"v1",
+	"apps/v1beta2",
+	"apps/v1beta1",
+	"autoscaling/v1",
+	"autoscaling/v2alpha1",
+	"batch/v1",
+	"batch/v2alpha1",
+	"extensions/v1beta1",
+}
+
+// SetGroupVersions sets the API group versions the server serves and
+// reports through discovery, e.g. to mimic an older cluster. Requests for
+// other group versions fail with NotFound.
+func (s *APIServer) SetGroupVersions(groupVersions ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.groupVersions = append([]string{}, groupVersions...)
+}
+
+func (s *APIServer) serves(groupVersion string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, served := range s.groupVersions {
+		if served == groupVersion {
+			return true
+		}
+	}
+
+	return false
+}
+
+// serveDiscovery answers the version and discovery requests, reporting
+// whether the path was one of them.
+func (s *APIServer) serveDiscovery(w http.ResponseWriter, path string) bool {
+	switch strings.Trim(path, "/") {
+	case "version":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"major":      "1",
+			"minor":      "8",
+			"gitVersion": "v1.8.0-orchestratortest",
+			"goVersion":  runtime.Version(),
+			"compiler":   runtime.Compiler,
+			"platform":   runtime.GOOS + "/" + runtime.GOARCH,
+		})
+	case "api":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"kind":     "APIVersions",
+			"versions": []string{"v1"},
+		})
+	case "apis":
+		writeJSON(w, http.StatusOK, map[string]interface{}{
+			"kind":       "APIGroupList",
+			"apiVersion": "v1",
+			"groups":     s.apiGroups(),
+		})
+	default:
+		return false
+	}
+
+	return true
+}
+
+// apiGroups lists the served named groups, in the order their versions
+// were given, the first version of each being the preferred one.
+func (s *APIServer) apiGroups() []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	groups := []interface{}{}
+	versions := map[string][]interface{}{}
+	for _, groupVersion := range s.groupVersions {
+		parts := strings.SplitN(groupVersion, "/", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		name := parts[0]
+		if _, seen := versions[name]; !seen {
+			groups = append(groups, name)
+		}
+		versions[name] = append(versions[name], map[string]interface{}{
+			"groupVersion": groupVersion,
+			"version":      parts[1],
+		})
+	}
+
+	for i, name := range groups {
+		groups[i] = map[string]interface{}{
+			"name":             name,
+			"versions":         versions[name.(string)],
+			"preferredVersion": versions[name.(string)][0],
+		}
+	}
+
+	return groups
+}
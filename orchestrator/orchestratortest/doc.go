@@ -0,0 +1,20 @@
+// Package orchestratortest provides an in-process stand-in for the
+// Kubernetes API server, so the orchestrators can be exercised offline.
+//
+// The server keeps objects in memory, serves list, get, create, update,
+// patch, delete and watch requests for the resources the orchestrators use,
+// and serves pod logs registered with SetLogs. Lists can be paged with limit
+// and continue. Discovery reports DefaultGroupVersions, or the ones given
+// to SetGroupVersions to mimic an older cluster. It runs no controllers: tests
+// play their part by seeding objects with Add, changing them with Modify, or
+// reacting to creations with OnCreate.
+//
+//	server := orchestratortest.NewAPIServer()
+//	defer server.Close()
+//
+//	server.OnCreate("jobs", func(job orchestratortest.Object) {
+//		// create the job's pod, set its logs and mark the job complete
+//	})
+//
+//	jobOrchestrator := orchestrator.NewJobOrchestrator(server.ClientSet(), "default")
+package orchestratortest
@@ -0,0 +1,158 @@
+package orchestratortest
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// resourceField holds the resource an object is stored under. It never
+// leaves the server.
+const resourceField = "__resource"
+
+func toObject(object interface{}) (Object, error) {
+	generic := Object{}
+	if err := convert(object, &generic); err != nil {
+		return nil, err
+	}
+
+	if generic.metadata() == nil {
+		generic["metadata"] = map[string]interface{}{}
+	}
+
+	return generic, nil
+}
+
+// convert copies a value into another through its JSON form.
+func convert(from, into interface{}) error {
+	fromJSON, err := json.Marshal(from)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(fromJSON, into)
+}
+
+func (o Object) copy() Object {
+	copied := Object{}
+	convert(o, &copied)
+	return copied
+}
+
+func (o Object) metadata() map[string]interface{} {
+	metadata, _ := o["metadata"].(map[string]interface{})
+	return metadata
+}
+
+func (o Object) name() string {
+	name, _ := o.metadata()["name"].(string)
+	return name
+}
+
+func (o Object) namespace() string {
+	namespace, _ := o.metadata()["namespace"].(string)
+	return namespace
+}
+
+func (o Object) resource() string {
+	resource, _ := o[resourceField].(string)
+	return resource
+}
+
+func (o Object) labels() map[string]string {
+	labels := map[string]string{}
+	generic, _ := o.metadata()["labels"].(map[string]interface{})
+	for key, value := range generic {
+		labels[key], _ = value.(string)
+	}
+
+	return labels
+}
+
+// field returns the string value at a dotted path such as "status.phase".
+func (o Object) field(path string) string {
+	var current interface{} = map[string]interface{}(o)
+	for _, part := range strings.Split(path, ".") {
+		parent, _ := current.(map[string]interface{})
+		current = parent[part]
+	}
+
+	value, _ := current.(string)
+	return value
+}
+
+func (o Object) firstContainer() string {
+	spec, _ := o["spec"].(map[string]interface{})
+	containers, _ := spec["containers"].([]interface{})
+	if len(containers) == 0 {
+		return ""
+	}
+
+	container, _ := containers[0].(map[string]interface{})
+	name, _ := container["name"].(string)
+	return name
+}
+
+// matches reports whether the object is in the namespace, an empty one
+// meaning any, and satisfies the label and field selectors.
+func (o Object) matches(namespace string, labels, fields selector) bool {
+	if namespace != "" && o.namespace() != namespace {
+		return false
+	}
+
+	return labels.matches(o.labels()) && fields.matches(fieldGetter(o.field))
+}
+
+// withType returns a copy of the object as served for the request, with
+// its kind and apiVersion set.
+func withType(o Object, req request) Object {
+	typed := o.copy()
+	delete(typed, resourceField)
+	typed["kind"] = resourceKinds[req.resource].kind
+	typed["apiVersion"] = req.groupVersion
+	return typed
+}
+
+func specJSON(o Object) string {
+	spec, _ := json.Marshal(o["spec"])
+	return string(spec)
+}
+
+func objectKey(resource, namespace, name string) string {
+	return resource + "/" + namespace + "/" + name
+}
+
+func sortedKeys(objects map[string]Object) []string {
+	keys := []string{}
+	for key := range objects {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// mergePatch applies a JSON merge patch (RFC 7386). Strategic merge
+// patches are applied the same way, which is enough for patches that do
+// not touch lists.
+func mergePatch(target, patch interface{}) interface{} {
+	patchMap, isMap := patch.(map[string]interface{})
+	if !isMap {
+		return patch
+	}
+
+	targetMap, isMap := target.(map[string]interface{})
+	if !isMap {
+		targetMap = map[string]interface{}{}
+	}
+
+	for key, value := range patchMap {
+		if value == nil {
+			delete(targetMap, key)
+			continue
+		}
+
+		targetMap[key] = mergePatch(targetMap[key], value)
+	}
+
+	return targetMap
+}
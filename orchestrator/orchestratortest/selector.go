@@ -0,0 +1,90 @@
+package orchestratortest
+
+import (
+	"fmt"
+	"strings"
+)
+
+// requirement is one term of a label or field selector.
+type requirement struct {
+	key      string
+	value    string
+	operator string
+}
+
+// selector is a parsed label or field selector. It supports the equality
+// based forms "key=value", "key==value", "key!=value", "key" and "!key".
+type selector []requirement
+
+type fieldGetter func(path string) string
+
+func parseSelector(expression string) (selector, error) {
+	parsed := selector{}
+	for _, term := range strings.Split(expression, ",") {
+		term = strings.TrimSpace(term)
+
+		switch {
+		case term == "":
+			continue
+		case strings.Contains(term, "!="):
+			parts := strings.SplitN(term, "!=", 2)
+			parsed = append(parsed, requirement{key: parts[0], value: parts[1], operator: "!="})
+		case strings.Contains(term, "=="):
+			parts := strings.SplitN(term, "==", 2)
+			parsed = append(parsed, requirement{key: parts[0], value: parts[1], operator: "="})
+		case strings.Contains(term, "="):
+			parts := strings.SplitN(term, "=", 2)
+			parsed = append(parsed, requirement{key: parts[0], value: parts[1], operator: "="})
+		case strings.ContainsAny(term, " ()<>"):
+			return nil, fmt.Errorf("unsupported selector %q", term)
+		case strings.HasPrefix(term, "!"):
+			parsed = append(parsed, requirement{key: term[1:], operator: "!"})
+		default:
+			parsed = append(parsed, requirement{key: term, operator: "exists"})
+		}
+	}
+
+	return parsed, nil
+}
+
+// matches checks a selector against either a label set or, for field
+// selectors, a fieldGetter.
+func (s selector) matches(source interface{}) bool {
+	for _, req := range s {
+		value, found := lookup(source, req.key)
+
+		switch req.operator {
+		case "=":
+			if !found || value != req.value {
+				return false
+			}
+		case "!=":
+			if found && value == req.value {
+				return false
+			}
+		case "exists":
+			if !found {
+				return false
+			}
+		case "!":
+			if found {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func lookup(source interface{}, key string) (string, bool) {
+	switch typed := source.(type) {
+	case map[string]string:
+		value, found := typed[key]
+		return value, found
+	case fieldGetter:
+		value := typed(key)
+		return value, true
+	}
+
+	return "", false
+}
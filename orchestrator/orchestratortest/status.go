@@ -0,0 +1,44 @@
+package orchestratortest
+
+import (
+	"net/http"
+)
+
+// status is the metav1.Status body the API server sends with errors.
+type status struct {
+	code    int
+	reason  string
+	kind    string
+	name    string
+	message string
+}
+
+func newStatus(code int, reason, kind, name, message string) *status {
+	return &status{code: code, reason: reason, kind: kind, name: name, message: message}
+}
+
+func notFound(req request) *status {
+	return newStatus(http.StatusNotFound, "NotFound", req.resource, req.name,
+		req.resource+" \""+req.name+"\" not found")
+}
+
+func writeStatus(w http.ResponseWriter, s *status) {
+	outcome := "Failure"
+	if s.code < http.StatusBadRequest {
+		outcome = "Success"
+	}
+
+	writeJSON(w, s.code, map[string]interface{}{
+		"kind":       "Status",
+		"apiVersion": "v1",
+		"metadata":   map[string]interface{}{},
+		"status":     outcome,
+		"message":    s.message,
+		"reason":     s.reason,
+		"code":       s.code,
+		"details": map[string]interface{}{
+			"name": s.name,
+			"kind": s.kind,
+		},
+	})
+}
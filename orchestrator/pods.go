@@ -1,35 +1,104 @@
 package orchestrator
 
 import (
-	"fmt"
+	"io"
+	"os"
+	"sort"
 
 	apiv1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/kubernetes"
 )
 
 type PodOrchestrator struct {
-	KubernetesClientSet *kubernetes.Clientset
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
 }
 
-func NewPodOrchestrator(kubernetesClientSet *kubernetes.Clientset) *PodOrchestrator {
+func NewPodOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *PodOrchestrator {
 	return &PodOrchestrator{
 		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
 	}
 }
 
-func (p PodOrchestrator) List() {
-	podInterface := p.KubernetesClientSet.Pods(apiv1.NamespaceDefault)
+// List returns the pods selected by the options.
+func (p PodOrchestrator) List(options ListOptions) ([]apiv1.Pod, error) {
+	podInterface := p.KubernetesClientSet.CoreV1().Pods(listNamespace(p.Namespace, options.AllNamespaces))
 
-	podList, err := podInterface.List(metav1.ListOptions{})
+	var pods []apiv1.Pod
+	err := listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := podInterface.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		pods = append(pods, list.Items...)
+		return list.Continue, nil
+	})
+	if err != nil {
+		return nil, wrapError("list", "pods", "", err)
+	}
+
+	return pods, nil
+}
+
+// Describe returns a pod along with the events about it, oldest first.
+func (p PodOrchestrator) Describe(name string) (*apiv1.Pod, []apiv1.Event, error) {
+	pod, err := p.KubernetesClientSet.CoreV1().Pods(p.Namespace).Get(name, metav1.GetOptions{})
 	if err != nil {
-		fmt.Println("Error on get pods")
-		return
+		return nil, nil, wrapError("describe", "pod", name, err)
 	}
 
-	for _, pod := range podList.Items {
-		fmt.Println("pod.UID: ", pod.UID)
-		fmt.Println("pod.Name: ", pod.Name)
-		fmt.Println("pod.Labels: ", pod.Labels)
+	events, err := p.events(pod)
+	if err != nil {
+		return nil, nil, wrapError("describe", "pod", name, err)
 	}
+
+	return pod, events, nil
+}
+
+// PrintLogs prints the logs of a container of the pod, streaming them
+// with options.Follow. The container may be left empty for pods with a
+// single container.
+func (p PodOrchestrator) PrintLogs(name, container string, options LogOptions) error {
+	podLogOptions := options.podLogOptions(container)
+	podLogOptions.Previous = options.Previous
+
+	stream, err := p.KubernetesClientSet.CoreV1().Pods(p.Namespace).GetLogs(name, podLogOptions).Stream()
+	if err != nil {
+		return wrapError("get logs of", "pod", name, err)
+	}
+	defer stream.Close()
+
+	if _, err := io.Copy(os.Stdout, stream); err != nil {
+		return wrapError("get logs of", "pod", name, err)
+	}
+
+	return nil
+}
+
+// events lists the events whose involved object is the pod.
+func (p PodOrchestrator) events(pod *apiv1.Pod) ([]apiv1.Event, error) {
+	selector := fields.Set{
+		"involvedObject.kind":      "Pod",
+		"involvedObject.name":      pod.Name,
+		"involvedObject.namespace": pod.Namespace,
+		"involvedObject.uid":       string(pod.UID),
+	}.AsSelector()
+
+	eventList, err := p.KubernetesClientSet.CoreV1().Events(pod.Namespace).List(metav1.ListOptions{
+		FieldSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	events := eventList.Items
+	sort.Slice(events, func(a, b int) bool {
+		return events[a].LastTimestamp.Before(&events[b].LastTimestamp)
+	})
+
+	return events, nil
 }
@@ -0,0 +1,147 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	apiBatchv1 "k8s.io/api/batch/v1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CleanupPolicy sets which jobs Run deletes, along with their pods, once
+// they have finished and their output was printed.
+type CleanupPolicy string
+
+const (
+	// CleanupNever keeps every job.
+	CleanupNever CleanupPolicy = "never"
+
+	// CleanupOnSuccess deletes jobs that succeeded and keeps failed ones
+	// around for inspection.
+	CleanupOnSuccess CleanupPolicy = "on-success"
+
+	// CleanupAlways deletes every job.
+	CleanupAlways CleanupPolicy = "always"
+)
+
+// ParseCleanupPolicy converts a -cleanup flag value into a CleanupPolicy.
+func ParseCleanupPolicy(value string) (CleanupPolicy, error) {
+	switch policy := CleanupPolicy(value); policy {
+	case CleanupNever, CleanupOnSuccess, CleanupAlways:
+		return policy, nil
+	}
+
+	return "", fmt.Errorf("unknown cleanup policy %q, must be one of: never | on-success | always", value)
+}
+
+func (p CleanupPolicy) applies(result *JobResult) bool {
+	switch p {
+	case CleanupAlways:
+		return true
+	case CleanupOnSuccess:
+		return result.Complete
+	default:
+		return false
+	}
+}
+
+// PruneOptions selects the finished jobs Prune deletes. A job is deleted
+// when either criterion selects it; at least one must be set.
+type PruneOptions struct {
+	// OlderThan selects jobs that finished longer ago than this. Zero
+	// disables the criterion.
+	OlderThan time.Duration
+
+	// KeepLast selects all but this many of the most recently finished
+	// jobs. Zero disables the criterion.
+	KeepLast int
+
+	// DryRun only returns the jobs that would be deleted.
+	DryRun bool
+}
+
+// Prune deletes the complete and failed jobs selected by the options,
+// along with their pods, and returns them. Only jobs created by Run are
+// considered: jobs still running, jobs owned by a cron job, which prunes
+// its own history, and jobs created by anyone else are left alone.
+func (j JobOrchestrator) Prune(options PruneOptions) ([]apiBatchv1.Job, error) {
+	if options.OlderThan <= 0 && options.KeepLast <= 0 {
+		return nil, newError("prune", "jobs", "", ReasonInvalid, errors.New("neither a retention window nor a number of jobs to keep specified"))
+	}
+
+	jobList, err := j.KubernetesClientSet.BatchV1().Jobs(j.Namespace).List(metav1.ListOptions{
+		LabelSelector: createdByLabel + "=" + createdByValue,
+	})
+	if err != nil {
+		return nil, wrapError("prune", "jobs", "", err)
+	}
+
+	var finished []apiBatchv1.Job
+	for _, job := range jobList.Items {
+		if _, done := jobFinishTime(&job); done && metav1.GetControllerOf(&job) == nil {
+			finished = append(finished, job)
+		}
+	}
+
+	// Most recently finished first, so the jobs to keep come first.
+	sort.Slice(finished, func(a, b int) bool {
+		finishedA, _ := jobFinishTime(&finished[a])
+		finishedB, _ := jobFinishTime(&finished[b])
+		return finishedA.After(finishedB)
+	})
+
+	cutoff := time.Now().Add(-options.OlderThan)
+	var pruned []apiBatchv1.Job
+	for i, job := range finished {
+		finishTime, _ := jobFinishTime(&job)
+		expired := options.OlderThan > 0 && finishTime.Before(cutoff)
+		surplus := options.KeepLast > 0 && i >= options.KeepLast
+		if expired || surplus {
+			pruned = append(pruned, job)
+		}
+	}
+
+	if options.DryRun {
+		return pruned, nil
+	}
+
+	for _, job := range pruned {
+		if err := j.Delete(job.Name); err != nil && !IsNotFound(err) {
+			return pruned, err
+		}
+	}
+
+	return pruned, nil
+}
+
+// Delete deletes the job along with its pods.
+func (j JobOrchestrator) Delete(jobName string) error {
+	deletePolicy := metav1.DeletePropagationForeground
+	if err := j.KubernetesClientSet.BatchV1().Jobs(j.Namespace).Delete(jobName, &metav1.DeleteOptions{
+		PropagationPolicy: &deletePolicy,
+	}); err != nil {
+		return wrapError("delete", "job", jobName, err)
+	}
+
+	fmt.Printf("Deleted job %s\n", jobName)
+	return nil
+}
+
+// jobFinishTime returns when the job became complete or failed, or false
+// while it is still running.
+func jobFinishTime(job *apiBatchv1.Job) (time.Time, bool) {
+	for _, condition := range job.Status.Conditions {
+		if condition.Status != apiv1.ConditionTrue {
+			continue
+		}
+
+		if condition.Type == apiBatchv1.JobComplete || condition.Type == apiBatchv1.JobFailed {
+			return condition.LastTransitionTime.Time, true
+		}
+	}
+
+	return time.Time{}, false
+}
@@ -0,0 +1,30 @@
+package orchestrator
+
+import (
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	conflictRetries = 5
+	conflictBackoff = 100 * time.Millisecond
+)
+
+// retryOnConflict runs fn again while the API server rejects the write
+// because the object changed since it was read (resourceVersion conflict).
+func retryOnConflict(fn func() error) error {
+	var err error
+	for attempt := 1; attempt <= conflictRetries; attempt++ {
+		err = fn()
+		if !errors.IsConflict(err) {
+			return err
+		}
+
+		fmt.Printf("Conflict on attempt %d, retrying...\n", attempt)
+		time.Sleep(time.Duration(attempt) * conflictBackoff)
+	}
+
+	return err
+}
@@ -0,0 +1,245 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Annotation the deployment controller sets on each ReplicaSet it owns.
+	revisionAnnotation = "deployment.kubernetes.io/revision"
+
+	// Label the deployment controller adds to the pod template of each ReplicaSet.
+	podTemplateHashLabel = "pod-template-hash"
+
+	// Reason set on the Progressing condition once progressDeadlineSeconds passes without progress.
+	progressDeadlineExceeded = "ProgressDeadlineExceeded"
+
+	// Upper bound for deployments that have no progressDeadlineSeconds.
+	rolloutTimeout = 10 * time.Minute
+
+	// Extra time given to the controller to report an exceeded progress
+	// deadline before the watch gives up on its own.
+	rolloutTimeoutSlack = time.Minute
+)
+
+// RolloutStatus watches a deployment and reports its progress until the
+// rollout completes. It fails when the rollout stalls past the deployment's
+// progressDeadlineSeconds.
+func (d DeploymentOrchestrator) RolloutStatus(deployName string) error {
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("get rollout status of", "deployment", deployName, err)
+	}
+
+	deployment, err := deploymentsClient.Get(deployName, metav1.GetOptions{})
+	if err != nil {
+		return wrapError("get rollout status of", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Waiting for deployment %q rollout to finish...\n", deployName)
+	err = d.waitFor(deployment, rolloutWaitTimeout(deployment), rolloutComplete)
+	if err != nil {
+		return wrapError("get rollout status of", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Deployment %q successfully rolled out.\n", deployName)
+	return nil
+}
+
+// rolloutWaitTimeout is how long RolloutStatus waits for the deployment:
+// its progress deadline plus some slack, or rolloutTimeout when it has none.
+func rolloutWaitTimeout(deployment *appsv1beta2.Deployment) time.Duration {
+	if deployment.Spec.ProgressDeadlineSeconds == nil {
+		return rolloutTimeout
+	}
+
+	return time.Duration(*deployment.Spec.ProgressDeadlineSeconds)*time.Second + rolloutTimeoutSlack
+}
+
+// rolloutComplete prints the deployment progress and reports whether the
+// rollout is done, following the same rules as kubectl rollout status.
+func rolloutComplete(deployment *appsv1beta2.Deployment) (bool, error) {
+	status := deployment.Status
+	fmt.Printf("generation %d (observed %d): %d updated, %d ready, %d available, %d unavailable\n",
+		deployment.Generation, status.ObservedGeneration, status.UpdatedReplicas,
+		status.ReadyReplicas, status.AvailableReplicas, status.UnavailableReplicas)
+
+	// Conditions from before the controller saw the latest spec describe
+	// the previous rollout, so a stale ProgressDeadlineExceeded must not
+	// fail this one.
+	if status.ObservedGeneration < deployment.Generation {
+		return false, nil
+	}
+
+	for _, condition := range status.Conditions {
+		if condition.Type != appsv1beta2.DeploymentProgressing && condition.Type != appsv1beta2.DeploymentAvailable {
+			continue
+		}
+
+		fmt.Printf("  %s=%s %s: %s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+		if condition.Type == appsv1beta2.DeploymentProgressing && condition.Reason == progressDeadlineExceeded {
+			return true, newError("roll out", "deployment", deployment.Name, ReasonTimeout, errors.New("progress deadline exceeded"))
+		}
+	}
+
+	var replicas int32 = 1
+	if deployment.Spec.Replicas != nil {
+		replicas = *deployment.Spec.Replicas
+	}
+
+	return status.UpdatedReplicas >= replicas &&
+		status.Replicas <= status.UpdatedReplicas &&
+		status.AvailableReplicas >= status.UpdatedReplicas, nil
+}
+
+// DeploymentRevision is one entry of a deployment's rollout history.
+type DeploymentRevision struct {
+	Revision   int64
+	ReplicaSet string
+	Template   apiv1.PodTemplateSpec
+}
+
+// History lists the revisions of a deployment, oldest first, printing what
+// changed in the pod template from one revision to the next.
+func (d DeploymentOrchestrator) History(deployName string) ([]DeploymentRevision, error) {
+	revisions, err := d.revisions(deployName)
+	if err != nil {
+		return nil, wrapError("get history of", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Revisions of deployment %q:\n", deployName)
+	for i, revision := range revisions {
+		fmt.Printf("* revision %d (replica set %s)\n", revision.Revision, revision.ReplicaSet)
+		if i == 0 {
+			continue
+		}
+
+		changes, err := diffObjects(revisions[i-1].Template, revision.Template)
+		if err != nil {
+			return nil, wrapError("get history of", "deployment", deployName, err)
+		}
+
+		for _, change := range changes {
+			fmt.Println("    ", change)
+		}
+	}
+
+	return revisions, nil
+}
+
+// Rollback restores the pod template of the given revision. Revision 0
+// means the one before the current revision.
+func (d DeploymentOrchestrator) Rollback(deployName string, revision int64) error {
+	revisions, err := d.revisions(deployName)
+	if err != nil {
+		return wrapError("roll back", "deployment", deployName, err)
+	}
+
+	target, err := findRevision(revisions, revision)
+	if err != nil {
+		return newError("roll back", "deployment", deployName, ReasonNotFound, err)
+	}
+
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return wrapError("roll back", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Rolling back deployment %q to revision %d...\n", deployName, target.Revision)
+	err = retryOnConflict(func() error {
+		deployment, err := deploymentsClient.Get(deployName, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+
+		deployment.Spec.Template = target.Template
+		_, err = deploymentsClient.Update(deployment)
+		return err
+	})
+	if err != nil {
+		return wrapError("roll back", "deployment", deployName, err)
+	}
+
+	fmt.Printf("Rolled back deployment %q.\n", deployName)
+	return nil
+}
+
+// revisions collects the ReplicaSets controlled by the deployment, sorted
+// by revision.
+func (d DeploymentOrchestrator) revisions(deployName string) ([]DeploymentRevision, error) {
+	deploymentsClient, err := d.deployments(d.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	deployment, err := deploymentsClient.Get(deployName, metav1.GetOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	selector, err := metav1.LabelSelectorAsSelector(deployment.Spec.Selector)
+	if err != nil {
+		return nil, err
+	}
+
+	replicaSetList, err := d.KubernetesClientSet.ExtensionsV1beta1().ReplicaSets(deployment.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	revisions := []DeploymentRevision{}
+	for _, replicaSet := range replicaSetList.Items {
+		owner := metav1.GetControllerOf(&replicaSet)
+		if owner == nil || owner.UID != deployment.UID {
+			continue
+		}
+
+		revision, err := strconv.ParseInt(replicaSet.Annotations[revisionAnnotation], 10, 64)
+		if err != nil {
+			continue
+		}
+
+		template := *replicaSet.Spec.Template.DeepCopy()
+		delete(template.Labels, podTemplateHashLabel)
+
+		revisions = append(revisions, DeploymentRevision{
+			Revision:   revision,
+			ReplicaSet: replicaSet.Name,
+			Template:   template,
+		})
+	}
+
+	sort.Slice(revisions, func(i, j int) bool {
+		return revisions[i].Revision < revisions[j].Revision
+	})
+
+	return revisions, nil
+}
+
+func findRevision(revisions []DeploymentRevision, revision int64) (DeploymentRevision, error) {
+	if revision == 0 {
+		if len(revisions) < 2 {
+			return DeploymentRevision{}, errors.New("no previous revision to roll back to")
+		}
+
+		return revisions[len(revisions)-2], nil
+	}
+
+	for _, candidate := range revisions {
+		if candidate.Revision == revision {
+			return candidate, nil
+		}
+	}
+
+	return DeploymentRevision{}, fmt.Errorf("revision %d not found", revision)
+}
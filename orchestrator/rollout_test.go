@@ -0,0 +1,180 @@
+package orchestrator
+
+import (
+	"fmt"
+	"testing"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	apiv1 "k8s.io/api/core/v1"
+	extensionsv1beta1 "k8s.io/api/extensions/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+// revisionReplicaSet returns the replica set the deployment controller
+// would keep for a revision of the deployment, running the given image.
+func revisionReplicaSet(owner *appsv1beta2.Deployment, revision int, image string) *extensionsv1beta1.ReplicaSet {
+	isController := true
+	hash := fmt.Sprintf("hash%d", revision)
+	podLabels := map[string]string{"app": "web", podTemplateHashLabel: hash}
+
+	return &extensionsv1beta1.ReplicaSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        owner.Name + "-" + hash,
+			Namespace:   owner.Namespace,
+			Labels:      podLabels,
+			Annotations: map[string]string{revisionAnnotation: fmt.Sprint(revision)},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: AppsV1beta2,
+				Kind:       "Deployment",
+				Name:       owner.Name,
+				UID:        owner.UID,
+				Controller: &isController,
+			}},
+		},
+		Spec: extensionsv1beta1.ReplicaSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: podLabels},
+			Template: apiv1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: podLabels},
+				Spec: apiv1.PodSpec{
+					Containers: []apiv1.Container{{Name: "web", Image: image}},
+				},
+			},
+		},
+	}
+}
+
+// newRolloutTestServer serves a web deployment with revisions 1 to 3,
+// added out of order, and a replica set of the same app the deployment
+// does not own.
+func newRolloutTestServer(t *testing.T) (*orchestratortest.APIServer, *DeploymentOrchestrator) {
+	server := orchestratortest.NewAPIServer()
+
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web", Image: "nginx:1.13"}); err != nil {
+		server.Close()
+		t.Fatalf("Create: %v", err)
+	}
+
+	var deployment appsv1beta2.Deployment
+	if err := server.Get("deployments", "default", "web", &deployment); err != nil {
+		server.Close()
+		t.Fatalf("Get: %v", err)
+	}
+
+	orphan := revisionReplicaSet(&deployment, 9, "nginx:0.1")
+	orphan.Name = "web-orphan"
+	orphan.OwnerReferences = nil
+
+	for _, replicaSet := range []*extensionsv1beta1.ReplicaSet{
+		revisionReplicaSet(&deployment, 3, "nginx:1.13"),
+		revisionReplicaSet(&deployment, 1, "nginx:1.11"),
+		orphan,
+		revisionReplicaSet(&deployment, 2, "nginx:1.12"),
+	} {
+		if err := server.Add("replicasets", replicaSet); err != nil {
+			server.Close()
+			t.Fatalf("Add: %v", err)
+		}
+	}
+
+	return server, deployments
+}
+
+func deploymentImage(t *testing.T, server *orchestratortest.APIServer) string {
+	var deployment appsv1beta2.Deployment
+	if err := server.Get("deployments", "default", "web", &deployment); err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	return deployment.Spec.Template.Spec.Containers[0].Image
+}
+
+func TestDeploymentHistory(t *testing.T) {
+	server, deployments := newRolloutTestServer(t)
+	defer server.Close()
+
+	revisions, err := deployments.History("web")
+	if err != nil {
+		t.Fatalf("History: %v", err)
+	}
+
+	if len(revisions) != 3 {
+		t.Fatalf("History: got %d revisions, want 3", len(revisions))
+	}
+
+	for i, revision := range revisions {
+		want := int64(i + 1)
+		if revision.Revision != want || revision.ReplicaSet != fmt.Sprintf("web-hash%d", want) {
+			t.Errorf("History: got revision %d from %s at position %d, want revision %d", revision.Revision, revision.ReplicaSet, i, want)
+		}
+
+		if _, found := revision.Template.Labels[podTemplateHashLabel]; found {
+			t.Errorf("History: revision %d template keeps the %s label", revision.Revision, podTemplateHashLabel)
+		}
+	}
+}
+
+func TestDeploymentRollback(t *testing.T) {
+	server, deployments := newRolloutTestServer(t)
+	defer server.Close()
+
+	// Revision 0 is the one before the current revision 3.
+	if err := deployments.Rollback("web", 0); err != nil {
+		t.Fatalf("Rollback to the previous revision: %v", err)
+	}
+
+	if image := deploymentImage(t, server); image != "nginx:1.12" {
+		t.Errorf("Rollback to the previous revision: got image %s, want nginx:1.12", image)
+	}
+
+	if err := deployments.Rollback("web", 1); err != nil {
+		t.Fatalf("Rollback to revision 1: %v", err)
+	}
+
+	if image := deploymentImage(t, server); image != "nginx:1.11" {
+		t.Errorf("Rollback to revision 1: got image %s, want nginx:1.11", image)
+	}
+
+	for _, revision := range []int64{4, 9} {
+		if err := deployments.Rollback("web", revision); !IsNotFound(err) {
+			t.Errorf("Rollback to revision %d: got %v, want a NotFound error", revision, err)
+		}
+	}
+
+	if image := deploymentImage(t, server); image != "nginx:1.11" {
+		t.Errorf("after failed rollbacks: got image %s, want nginx:1.11", image)
+	}
+}
+
+func TestFindRevision(t *testing.T) {
+	revisions := []DeploymentRevision{{Revision: 2}, {Revision: 5}}
+
+	tests := []struct {
+		revisions []DeploymentRevision
+		revision  int64
+		want      int64
+	}{
+		{revisions, 0, 2},
+		{revisions, 2, 2},
+		{revisions, 5, 5},
+		{revisions, 3, -1},
+		{revisions[1:], 0, -1},
+		{nil, 0, -1},
+	}
+
+	for _, test := range tests {
+		found, err := findRevision(test.revisions, test.revision)
+		if test.want < 0 {
+			if err == nil {
+				t.Errorf("findRevision(%v, %d) = revision %d, want an error", test.revisions, test.revision, found.Revision)
+			}
+			continue
+		}
+
+		if err != nil || found.Revision != test.want {
+			t.Errorf("findRevision(%v, %d) = revision %d, %v, want revision %d", test.revisions, test.revision, found.Revision, err, test.want)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package orchestrator
+
+import (
+	"sort"
+
+	"k8s.io/apimachinery/pkg/version"
+	"k8s.io/client-go/kubernetes"
+)
+
+// ServerInfo describes the API server the orchestrators talk to.
+type ServerInfo struct {
+	Version *version.Info
+
+	// GroupVersions lists the API group versions the server serves, such
+	// as "v1" or "apps/v1beta2", sorted.
+	GroupVersions []string
+
+	// DeploymentVersion is the deployment API version DeploymentOrchestrator
+	// picks by default, or empty when the server supports none.
+	DeploymentVersion string
+}
+
+// GetServerInfo queries the version of the server and, through discovery,
+// the API group versions it serves.
+func GetServerInfo(clientSet kubernetes.Interface) (*ServerInfo, error) {
+	serverVersion, err := clientSet.Discovery().ServerVersion()
+	if err != nil {
+		return nil, wrapError("get", "server version", "", err)
+	}
+
+	groups, err := clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return nil, wrapError("discover", "API groups", "", err)
+	}
+
+	served := groupVersions(groups)
+	sort.Strings(served)
+
+	return &ServerInfo{
+		Version:           serverVersion,
+		GroupVersions:     served,
+		DeploymentVersion: preferredDeploymentVersion(served),
+	}, nil
+}
+
+// servesGroupVersion asks the server, through discovery, whether it serves
+// an API group version such as "batch/v2alpha1". Alpha ones are off unless
+// enabled on the API server.
+func servesGroupVersion(clientSet kubernetes.Interface, groupVersion string) (bool, error) {
+	groups, err := clientSet.Discovery().ServerGroups()
+	if err != nil {
+		return false, err
+	}
+
+	for _, served := range groupVersions(groups) {
+		if served == groupVersion {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
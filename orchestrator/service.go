@@ -4,23 +4,39 @@ import (
 	"fmt"
 
 	apiv1 "k8s.io/api/core/v1"
-	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/intstr"
 	"k8s.io/client-go/kubernetes"
 )
 
 type ServiceOrchestrator struct {
-	KubernetesClientSet *kubernetes.Clientset
+	KubernetesClientSet kubernetes.Interface
+	Namespace           string
+
+	// Validation sets whether Create checks that the service selects
+	// running workloads. It defaults to ValidationWarn.
+	Validation ValidationMode
+
+	// DryRun makes Create and Delete only print the service they would
+	// send or delete and, for Create, the fields it would change in the
+	// live service.
+	DryRun bool
+
+	// discovered caches the deployment API version validation lists
+	// deployments with, as in DeploymentOrchestrator.
+	discovered *discoveredVersion
 }
 
-func NewServiceOrchestrator(kubernetesClientSet *kubernetes.Clientset) *ServiceOrchestrator {
+func NewServiceOrchestrator(kubernetesClientSet kubernetes.Interface, namespace string) *ServiceOrchestrator {
 	return &ServiceOrchestrator{
 		KubernetesClientSet: kubernetesClientSet,
+		Namespace:           namespace,
+		Validation:          ValidationWarn,
+		discovered:          &discoveredVersion{},
 	}
 }
 
-func (s ServiceOrchestrator) Create(serviceName, appName string, appPort int) {
+func (s ServiceOrchestrator) Create(serviceName, appName string, appPort int) error {
 	serviceSpec := &apiv1.Service{
 		TypeMeta: metav1.TypeMeta{
 			Kind:       "Service",
@@ -45,55 +61,69 @@ func (s ServiceOrchestrator) Create(serviceName, appName string, appPort int) {
 		},
 	}
 
+	if err := s.checkService(serviceSpec); err != nil {
+		return err
+	}
+
 	// Implement service update-or-create semantics.
-	service := s.KubernetesClientSet.Core().Services(apiv1.NamespaceDefault)
-	svc, err := service.Get(serviceName, metav1.GetOptions{})
-	switch {
-	case err == nil:
-		serviceSpec.ObjectMeta.ResourceVersion = svc.ObjectMeta.ResourceVersion
-		serviceSpec.Spec.ClusterIP = svc.Spec.ClusterIP
-
-		_, err = service.Update(serviceSpec)
-		if err != nil {
-			fmt.Printf("failed to update service: %s", err)
-			return
-		}
+	service := s.KubernetesClientSet.Core().Services(s.Namespace)
+	if s.DryRun {
+		return printDryRun("create or update", "service", serviceName, serviceSpec, func() (metav1.Object, error) {
+			return service.Get(serviceName, metav1.GetOptions{})
+		})
+	}
 
-		fmt.Println("service updated")
-	case errors.IsNotFound(err):
-		_, err = service.Create(serviceSpec)
-		if err != nil {
-			fmt.Printf("failed to create service: %s", err)
-			return
-		}
+	return createOrUpdate("service", serviceName,
+		func() (metav1.Object, error) {
+			return service.Get(serviceName, metav1.GetOptions{})
+		},
+		func() error {
+			_, err := service.Create(serviceSpec)
+			return err
+		},
+		func(live metav1.Object) error {
+			serviceSpec.ObjectMeta.ResourceVersion = live.GetResourceVersion()
+			serviceSpec.Spec.ClusterIP = live.(*apiv1.Service).Spec.ClusterIP
 
-		fmt.Println("service created")
-	default:
-		fmt.Printf("unexpected error: %s", err)
-	}
+			_, err := service.Update(serviceSpec)
+			return err
+		},
+	)
 }
 
-func (s ServiceOrchestrator) Delete(serviceName string) {
-	service := s.KubernetesClientSet.Core().Services(apiv1.NamespaceDefault)
+func (s ServiceOrchestrator) Delete(serviceName string) error {
+	service := s.KubernetesClientSet.Core().Services(s.Namespace)
+	if s.DryRun {
+		return printDryRunDelete("service", serviceName, func() (metav1.Object, error) {
+			return service.Get(serviceName, metav1.GetOptions{})
+		})
+	}
 
 	if err := service.Delete(serviceName, &metav1.DeleteOptions{}); err != nil {
-		fmt.Println("Error on delete service")
-		return
+		return wrapError("delete", "service", serviceName, err)
 	}
 
 	fmt.Println("Service deleted")
+	return nil
 }
 
-func (s ServiceOrchestrator) List() {
-	service := s.KubernetesClientSet.Core().Services(apiv1.NamespaceDefault)
+// List returns the services selected by the options.
+func (s ServiceOrchestrator) List(options ListOptions) ([]apiv1.Service, error) {
+	servicesClient := s.KubernetesClientSet.Core().Services(listNamespace(s.Namespace, options.AllNamespaces))
 
-	serviceList, err := service.List(metav1.ListOptions{})
+	var services []apiv1.Service
+	err := listPages(options, func(apiOptions metav1.ListOptions) (string, error) {
+		list, err := servicesClient.List(apiOptions)
+		if err != nil {
+			return "", err
+		}
+
+		services = append(services, list.Items...)
+		return list.Continue, nil
+	})
 	if err != nil {
-		fmt.Println("Error on list services")
-		return
+		return nil, wrapError("list", "services", "", err)
 	}
 
-	for _, service := range serviceList.Items {
-		fmt.Printf("* %s (Cluster IP: %s)\n", service.Name, service.Spec.ClusterIP)
-	}
+	return services, nil
 }
@@ -0,0 +1,152 @@
+package orchestrator
+
+import (
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/michelaquino/golang_kubernetes_example/orchestrator/orchestratortest"
+)
+
+func TestServiceCreateListDelete(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	services := NewServiceOrchestrator(server.ClientSet(), "default")
+	services.Validation = ValidationOff
+
+	if err := services.Create("web", "web", 8080); err != nil {
+		t.Fatalf("Create: %v", err)
+	}
+
+	if err := server.Modify("services", "default", "web", func(service orchestratortest.Object) {
+		service["spec"].(map[string]interface{})["clusterIP"] = "10.0.0.10"
+	}); err != nil {
+		t.Fatalf("Modify: %v", err)
+	}
+
+	// A second Create updates the service, keeping the cluster IP the
+	// server allocated.
+	if err := services.Create("web", "api", 9090); err != nil {
+		t.Fatalf("Create of an existing service: %v", err)
+	}
+
+	list, err := services.List(ListOptions{})
+	if err != nil {
+		t.Fatalf("List: %v", err)
+	}
+
+	if len(list) != 1 || list[0].Name != "web" {
+		t.Fatalf("List: got %d services, want only web", len(list))
+	}
+
+	service := list[0]
+	if service.Spec.ClusterIP != "10.0.0.10" {
+		t.Errorf("got cluster IP %q, want 10.0.0.10", service.Spec.ClusterIP)
+	}
+
+	if service.Spec.Selector["app"] != "api" {
+		t.Errorf("got selector %v, want app=api", service.Spec.Selector)
+	}
+
+	if len(service.Spec.Ports) != 1 || service.Spec.Ports[0].TargetPort.IntValue() != 9090 {
+		t.Errorf("got ports %+v, want one targeting port 9090", service.Spec.Ports)
+	}
+
+	if err := services.Delete("web"); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+
+	if list, err := services.List(ListOptions{}); err != nil || len(list) != 0 {
+		t.Errorf("List after Delete: got %d services and error %v, want none", len(list), err)
+	}
+}
+
+func TestServiceCreateStrictValidation(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	services := NewServiceOrchestrator(server.ClientSet(), "default")
+	services.Validation = ValidationStrict
+
+	if err := services.Create("web", "web", 8080); !IsInvalid(err) {
+		t.Errorf("Create of a service selecting nothing: got %v, want an Invalid error", err)
+	}
+
+	var service apiv1.Service
+	if err := server.Get("services", "default", "web", &service); err == nil {
+		t.Error("the service was created despite failing validation")
+	}
+
+	// A deployment scaled to zero has no pods to route traffic to.
+	noReplicas := int32(0)
+	deployments := NewDeploymentOrchestrator(server.ClientSet(), "default")
+	if err := deployments.Create(DeploymentSpec{Name: "web", AppName: "web", Port: 8080, Replicas: &noReplicas}); err != nil {
+		t.Fatalf("Create deployment: %v", err)
+	}
+
+	if err := services.Create("web", "web", 8080); !IsInvalid(err) {
+		t.Errorf("Create of a service selecting no pods: got %v, want an Invalid error", err)
+	}
+
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-1",
+			Namespace: "default",
+			Labels:    map[string]string{"app": "web"},
+		},
+		Spec: apiv1.PodSpec{
+			Containers: []apiv1.Container{{
+				Name:  "web",
+				Image: defaultImage,
+				Ports: []apiv1.ContainerPort{{ContainerPort: 8080}},
+			}},
+		},
+		Status: apiv1.PodStatus{Phase: apiv1.PodRunning},
+	}
+	if err := server.Add("pods", pod); err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+
+	if err := services.Create("web", "web", 8080); !IsInvalid(err) {
+		t.Errorf("Create of a service selecting a pod that is not ready: got %v, want an Invalid error", err)
+	}
+
+	if err := server.Modify("pods", "default", "web-1", func(pod orchestratortest.Object) {
+		pod["status"].(map[string]interface{})["conditions"] = []interface{}{
+			map[string]interface{}{"type": "Ready", "status": "True"},
+		}
+	}); err != nil {
+		t.Fatalf("Modify: %v", err)
+	}
+
+	if err := services.Create("web", "web", 8080); err != nil {
+		t.Errorf("Create of a service selecting a ready pod: %v", err)
+	}
+}
+
+func TestServiceCreateWhenValidationCannotRun(t *testing.T) {
+	server := orchestratortest.NewAPIServer()
+	defer server.Close()
+
+	// Without a deployments API, the checks cannot list deployments.
+	server.SetGroupVersions("v1")
+
+	services := NewServiceOrchestrator(server.ClientSet(), "default")
+	services.Validation = ValidationStrict
+
+	if err := services.Create("web", "web", 8080); err == nil {
+		t.Error("strict Create succeeded although the service could not be validated")
+	}
+
+	services.Validation = ValidationWarn
+	if err := services.Create("web", "web", 8080); err != nil {
+		t.Fatalf("Create with warnings: %v", err)
+	}
+
+	var service apiv1.Service
+	if err := server.Get("services", "default", "web", &service); err != nil {
+		t.Errorf("the service was not created: %v", err)
+	}
+}
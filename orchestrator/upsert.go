@@ -0,0 +1,40 @@
+package orchestrator
+
+import (
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// liveObject fetches the current state of an object from the API server.
+type liveObject func() (metav1.Object, error)
+
+// createOrUpdate creates the object when it does not exist yet, or calls
+// update with its live state so the caller can carry over server-owned
+// fields such as resourceVersion.
+func createOrUpdate(kind, name string, get liveObject, create func() error, update func(live metav1.Object) error) error {
+	err := retryOnConflict(func() error {
+		live, err := get()
+		switch {
+		case err == nil:
+			if err := update(live); err != nil {
+				return err
+			}
+
+			fmt.Printf("%s %q updated\n", kind, name)
+		case errors.IsNotFound(err):
+			if err := create(); err != nil {
+				return err
+			}
+
+			fmt.Printf("%s %q created\n", kind, name)
+		default:
+			return err
+		}
+
+		return nil
+	})
+
+	return wrapError("create or update", kind, name, err)
+}
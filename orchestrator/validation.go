@@ -0,0 +1,169 @@
+package orchestrator
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// ValidationMode sets what ServiceOrchestrator does when a service would
+// not route traffic anywhere.
+type ValidationMode string
+
+const (
+	// ValidationOff skips the checks.
+	ValidationOff ValidationMode = "off"
+
+	// ValidationWarn prints the problems found and goes on.
+	ValidationWarn ValidationMode = "warn"
+
+	// ValidationStrict refuses to create or update the service.
+	ValidationStrict ValidationMode = "strict"
+)
+
+// ParseValidationMode converts a -validate flag value into a ValidationMode.
+func ParseValidationMode(value string) (ValidationMode, error) {
+	switch mode := ValidationMode(value); mode {
+	case ValidationOff, ValidationWarn, ValidationStrict:
+		return mode, nil
+	}
+
+	return "", fmt.Errorf("unknown validation mode %q, must be one of: off | warn | strict", value)
+}
+
+// validateService resolves the service selector against the pods and
+// deployments of the namespace and reports the problems found: a selector
+// that matches no ready pod, which leaves the service without endpoints,
+// or target ports no matching container declares.
+func (s ServiceOrchestrator) validateService(service *apiv1.Service) ([]string, error) {
+	if len(service.Spec.Selector) == 0 {
+		return nil, nil
+	}
+
+	selector := labels.SelectorFromSet(labels.Set(service.Spec.Selector))
+
+	podList, err := s.KubernetesClientSet.CoreV1().Pods(s.Namespace).List(metav1.ListOptions{
+		LabelSelector: selector.String(),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	deployments := DeploymentOrchestrator{
+		KubernetesClientSet: s.KubernetesClientSet,
+		Namespace:           s.Namespace,
+		discovered:          s.discovered,
+	}
+	deploymentsClient, err := deployments.deployments(s.Namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	deploymentList, err := deploymentsClient.List(metav1.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+
+	podSpecs := []apiv1.PodSpec{}
+	ready := 0
+	for _, pod := range podList.Items {
+		podSpecs = append(podSpecs, pod.Spec)
+		if podReady(pod) {
+			ready++
+		}
+	}
+	for _, deployment := range deploymentList.Items {
+		if selector.Matches(labels.Set(deployment.Spec.Template.Labels)) {
+			podSpecs = append(podSpecs, deployment.Spec.Template.Spec)
+		}
+	}
+
+	if len(podSpecs) == 0 {
+		return []string{fmt.Sprintf("selector %s matches no pods or deployments in namespace %q", selector, s.Namespace)}, nil
+	}
+
+	problems := []string{}
+	if ready == 0 {
+		problems = append(problems, fmt.Sprintf("selector %s matches %d pods in namespace %q, none of them ready, so the service has no endpoints", selector, len(podList.Items), s.Namespace))
+	}
+
+	for _, port := range service.Spec.Ports {
+		if !declaresPort(podSpecs, port.TargetPort) {
+			problems = append(problems, fmt.Sprintf("target port %s matches no container port of the pods selected by %s", port.TargetPort.String(), selector))
+		}
+	}
+
+	return problems, nil
+}
+
+// podReady reports whether the pod is one the service would route traffic
+// to: ready and not being deleted.
+func podReady(pod apiv1.Pod) bool {
+	if pod.DeletionTimestamp != nil {
+		return false
+	}
+
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == apiv1.PodReady {
+			return condition.Status == apiv1.ConditionTrue
+		}
+	}
+
+	return false
+}
+
+func declaresPort(podSpecs []apiv1.PodSpec, targetPort intstr.IntOrString) bool {
+	for _, podSpec := range podSpecs {
+		for _, container := range podSpec.Containers {
+			for _, port := range container.Ports {
+				if targetPort.Type == intstr.Int && port.ContainerPort == targetPort.IntVal {
+					return true
+				}
+
+				if targetPort.Type == intstr.String && port.Name == targetPort.StrVal {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// checkService runs validateService according to the orchestrator's
+// validation mode. Only strict mode fails when the checks cannot run, for
+// instance when the user may not list pods; warn mode says so and goes on.
+func (s ServiceOrchestrator) checkService(service *apiv1.Service) error {
+	if s.Validation == ValidationOff {
+		return nil
+	}
+
+	problems, err := s.validateService(service)
+	if err != nil {
+		if s.Validation == ValidationStrict {
+			return wrapError("validate", "service", service.Name, err)
+		}
+
+		fmt.Printf("Warning: service %q: could not validate: %v\n", service.Name, err)
+		return nil
+	}
+
+	if len(problems) == 0 {
+		return nil
+	}
+
+	if s.Validation == ValidationStrict {
+		return newError("validate", "service", service.Name, ReasonInvalid, errors.New(strings.Join(problems, "; ")))
+	}
+
+	for _, problem := range problems {
+		fmt.Printf("Warning: service %q: %s\n", service.Name, problem)
+	}
+
+	return nil
+}
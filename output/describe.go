@@ -0,0 +1,144 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const timeFormat = time.RFC1123Z
+
+// DescribePod prints a pod in a readable form, in the manner of kubectl
+// describe: its placement and status, the state of each container with
+// its last termination, the pod conditions and the events about it.
+func DescribePod(w io.Writer, pod *apiv1.Pod, events []apiv1.Event) error {
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+
+	fmt.Fprintf(table, "Name:\t%s\n", pod.Name)
+	fmt.Fprintf(table, "Namespace:\t%s\n", pod.Namespace)
+	fmt.Fprintf(table, "Node:\t%s\n", valueOrNone(pod.Spec.NodeName))
+	if pod.Status.StartTime != nil {
+		fmt.Fprintf(table, "Start Time:\t%s\n", pod.Status.StartTime.Format(timeFormat))
+	}
+	fmt.Fprintf(table, "Labels:\t%s\n", valueOrNone(joinLabels(pod.Labels)))
+	fmt.Fprintf(table, "Status:\t%s\n", podStatus(*pod))
+	if pod.Status.Message != "" {
+		fmt.Fprintf(table, "Message:\t%s\n", pod.Status.Message)
+	}
+	fmt.Fprintf(table, "IP:\t%s\n", valueOrNone(pod.Status.PodIP))
+	if owner := metav1.GetControllerOf(pod); owner != nil {
+		fmt.Fprintf(table, "Controlled By:\t%s/%s\n", owner.Kind, owner.Name)
+	}
+
+	if len(pod.Spec.InitContainers) > 0 {
+		fmt.Fprintln(table, "Init Containers:")
+		describeContainers(table, pod.Spec.InitContainers, pod.Status.InitContainerStatuses)
+	}
+	fmt.Fprintln(table, "Containers:")
+	describeContainers(table, pod.Spec.Containers, pod.Status.ContainerStatuses)
+
+	fmt.Fprintln(table, "Conditions:")
+	fmt.Fprintln(table, "  Type\tStatus\tReason")
+	for _, condition := range pod.Status.Conditions {
+		fmt.Fprintf(table, "  %s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason)
+	}
+
+	if len(events) == 0 {
+		fmt.Fprintln(table, "Events:\t<none>")
+	}
+
+	if err := table.Flush(); err != nil {
+		return err
+	}
+
+	return describeEvents(w, events)
+}
+
+func describeContainers(w io.Writer, containers []apiv1.Container, statuses []apiv1.ContainerStatus) {
+	for _, container := range containers {
+		fmt.Fprintf(w, "  %s:\n", container.Name)
+		fmt.Fprintf(w, "    Image:\t%s\n", container.Image)
+		if len(container.Command) > 0 {
+			fmt.Fprintf(w, "    Command:\t%s\n", strings.Join(container.Command, " "))
+		}
+		if len(container.Args) > 0 {
+			fmt.Fprintf(w, "    Args:\t%s\n", strings.Join(container.Args, " "))
+		}
+
+		status := findContainerStatus(statuses, container.Name)
+		if status == nil {
+			continue
+		}
+
+		describeContainerState(w, "State", status.State)
+		if status.LastTerminationState.Terminated != nil {
+			describeContainerState(w, "Last State", status.LastTerminationState)
+		}
+		fmt.Fprintf(w, "    Ready:\t%t\n", status.Ready)
+		fmt.Fprintf(w, "    Restart Count:\t%d\n", status.RestartCount)
+	}
+}
+
+func describeContainerState(w io.Writer, title string, state apiv1.ContainerState) {
+	switch {
+	case state.Running != nil:
+		fmt.Fprintf(w, "    %s:\tRunning\n", title)
+		fmt.Fprintf(w, "      Started:\t%s\n", state.Running.StartedAt.Format(timeFormat))
+	case state.Waiting != nil:
+		fmt.Fprintf(w, "    %s:\tWaiting\n", title)
+		fmt.Fprintf(w, "      Reason:\t%s\n", valueOrNone(state.Waiting.Reason))
+		if state.Waiting.Message != "" {
+			fmt.Fprintf(w, "      Message:\t%s\n", state.Waiting.Message)
+		}
+	case state.Terminated != nil:
+		fmt.Fprintf(w, "    %s:\tTerminated\n", title)
+		fmt.Fprintf(w, "      Reason:\t%s\n", valueOrNone(state.Terminated.Reason))
+		if state.Terminated.Message != "" {
+			fmt.Fprintf(w, "      Message:\t%s\n", state.Terminated.Message)
+		}
+		fmt.Fprintf(w, "      Exit Code:\t%d\n", state.Terminated.ExitCode)
+		if state.Terminated.Signal != 0 {
+			fmt.Fprintf(w, "      Signal:\t%d\n", state.Terminated.Signal)
+		}
+		fmt.Fprintf(w, "      Started:\t%s\n", state.Terminated.StartedAt.Format(timeFormat))
+		fmt.Fprintf(w, "      Finished:\t%s\n", state.Terminated.FinishedAt.Format(timeFormat))
+	default:
+		fmt.Fprintf(w, "    %s:\tWaiting\n", title)
+	}
+}
+
+func describeEvents(w io.Writer, events []apiv1.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	fmt.Fprintln(w, "Events:")
+	table := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(table, "  TYPE\tREASON\tAGE\tCOUNT\tFROM\tMESSAGE")
+	for _, event := range events {
+		fmt.Fprintf(table, "  %s\t%s\t%s\t%d\t%s\t%s\n",
+			event.Type,
+			event.Reason,
+			age(event.LastTimestamp),
+			event.Count,
+			event.Source.Component,
+			strings.TrimSpace(event.Message))
+	}
+
+	return table.Flush()
+}
+
+func findContainerStatus(statuses []apiv1.ContainerStatus, name string) *apiv1.ContainerStatus {
+	for i := range statuses {
+		if statuses[i].Name == name {
+			return &statuses[i]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,32 @@
+package output
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDescribePodWithoutEvents(t *testing.T) {
+	pod := &apiv1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "web-1", Namespace: "default"},
+		Spec: apiv1.PodSpec{
+			Containers: []apiv1.Container{{Name: "web", Image: "nginx"}},
+		},
+	}
+
+	var output bytes.Buffer
+	if err := DescribePod(&output, pod, nil); err != nil {
+		t.Fatalf("DescribePod: %v", err)
+	}
+
+	if strings.Contains(output.String(), "\t") {
+		t.Errorf("DescribePod wrote a tab:\n%s", output.String())
+	}
+
+	if !strings.Contains(output.String(), "Events:") || !strings.HasSuffix(output.String(), "<none>\n") {
+		t.Errorf("DescribePod: got\n%s\nwant it to end with Events: <none>", output.String())
+	}
+}
@@ -0,0 +1,266 @@
+package output
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// jsonPath is a parsed JSONPath template, supporting the subset kubectl
+// users rely on most: field access (.a.b), quoted keys (['a.b']), array
+// indexes ([0]), wildcards ([*]), string literals ({"\n"}) and
+// {range ...}{end} blocks.
+type jsonPath struct {
+	nodes []jsonPathNode
+}
+
+type jsonPathNode struct {
+	text   string
+	path   []string
+	block  *jsonPath
+	isPath bool
+}
+
+func parseJSONPath(source string) (*jsonPath, error) {
+	parsed, rest, err := parseJSONPathNodes(source, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if rest != "" {
+		return nil, fmt.Errorf("unexpected {end} in jsonpath template")
+	}
+
+	return parsed, nil
+}
+
+// parseJSONPathNodes parses until the end of the source or, inside a range
+// block, until its {end}, returning what follows it.
+func parseJSONPathNodes(source string, inRange bool) (*jsonPath, string, error) {
+	parsed := &jsonPath{}
+
+	for source != "" {
+		open := strings.Index(source, "{")
+		if open < 0 {
+			parsed.nodes = append(parsed.nodes, jsonPathNode{text: source})
+			source = ""
+			break
+		}
+
+		if open > 0 {
+			parsed.nodes = append(parsed.nodes, jsonPathNode{text: source[:open]})
+		}
+
+		end := actionEnd(source[open:])
+		if end < 0 {
+			return nil, "", fmt.Errorf("unclosed action in jsonpath template")
+		}
+
+		action := strings.TrimSpace(source[open+1 : open+end])
+		source = source[open+end+1:]
+
+		switch {
+		case action == "end":
+			if !inRange {
+				return parsed, "{end}" + source, nil
+			}
+			return parsed, source, nil
+		case strings.HasPrefix(action, "range "):
+			path, err := parsePath(strings.TrimSpace(strings.TrimPrefix(action, "range ")))
+			if err != nil {
+				return nil, "", err
+			}
+
+			block, rest, err := parseJSONPathNodes(source, true)
+			if err != nil {
+				return nil, "", err
+			}
+
+			parsed.nodes = append(parsed.nodes, jsonPathNode{path: path, block: block, isPath: true})
+			source = rest
+		case strings.HasPrefix(action, `"`):
+			text, err := strconv.Unquote(action)
+			if err != nil {
+				return nil, "", fmt.Errorf("invalid string literal %s in jsonpath template", action)
+			}
+
+			parsed.nodes = append(parsed.nodes, jsonPathNode{text: text})
+		default:
+			path, err := parsePath(action)
+			if err != nil {
+				return nil, "", err
+			}
+
+			parsed.nodes = append(parsed.nodes, jsonPathNode{path: path, isPath: true})
+		}
+	}
+
+	if inRange {
+		return nil, "", fmt.Errorf("missing {end} in jsonpath template")
+	}
+
+	return parsed, "", nil
+}
+
+// actionEnd returns the index of the "}" closing the action source starts
+// with, skipping the ones inside quoted strings, or -1.
+func actionEnd(source string) int {
+	var quote byte
+	for i := 1; i < len(source); i++ {
+		switch {
+		case quote == 0 && (source[i] == '"' || source[i] == '\''):
+			quote = source[i]
+		case quote == 0 && source[i] == '}':
+			return i
+		case quote == '"' && source[i] == '\\':
+			i++
+		case quote != 0 && source[i] == quote:
+			quote = 0
+		}
+	}
+
+	return -1
+}
+
+// parsePath splits ".items[*].metadata.labels['app.kubernetes.io/name']"
+// into ["items", "*", "metadata", "labels", "app.kubernetes.io/name"].
+func parsePath(expression string) ([]string, error) {
+	invalid := fmt.Errorf("invalid jsonpath expression %q", expression)
+
+	rest := strings.TrimPrefix(expression, "$")
+	if rest == "." {
+		return []string{}, nil
+	}
+
+	path := []string{}
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			rest = rest[1:]
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			if end == 0 {
+				return nil, invalid
+			}
+
+			path = append(path, rest[:end])
+			rest = rest[end:]
+		case '[':
+			rest = rest[1:]
+			if rest != "" && (rest[0] == '\'' || rest[0] == '"') {
+				closing := strings.IndexByte(rest[1:], rest[0])
+				if closing < 0 || !strings.HasPrefix(rest[closing+2:], "]") {
+					return nil, invalid
+				}
+
+				path = append(path, rest[1:closing+1])
+				rest = rest[closing+3:]
+				continue
+			}
+
+			closing := strings.IndexByte(rest, ']')
+			if closing <= 0 {
+				return nil, invalid
+			}
+
+			path = append(path, rest[:closing])
+			rest = rest[closing+1:]
+		default:
+			return nil, invalid
+		}
+	}
+
+	return path, nil
+}
+
+func (j *jsonPath) execute(w io.Writer, data interface{}) error {
+	for _, node := range j.nodes {
+		if !node.isPath {
+			if _, err := io.WriteString(w, node.text); err != nil {
+				return err
+			}
+			continue
+		}
+
+		values, err := evaluatePath(data, node.path)
+		if err != nil {
+			return err
+		}
+
+		if node.block != nil {
+			for _, value := range values {
+				if err := node.block.execute(w, value); err != nil {
+					return err
+				}
+			}
+			continue
+		}
+
+		texts := []string{}
+		for _, value := range values {
+			texts = append(texts, formatValue(value))
+		}
+
+		if _, err := io.WriteString(w, strings.Join(texts, " ")); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func evaluatePath(data interface{}, path []string) ([]interface{}, error) {
+	current := []interface{}{data}
+
+	for _, step := range path {
+		next := []interface{}{}
+		for _, value := range current {
+			switch typed := value.(type) {
+			case map[string]interface{}:
+				if step == "*" {
+					for _, child := range typed {
+						next = append(next, child)
+					}
+				} else if child, found := typed[step]; found {
+					next = append(next, child)
+				}
+			case []interface{}:
+				if step == "*" {
+					next = append(next, typed...)
+					continue
+				}
+
+				index, err := strconv.Atoi(step)
+				if err != nil {
+					return nil, fmt.Errorf("invalid array index %q", step)
+				}
+				if index < 0 {
+					index += len(typed)
+				}
+				if index >= 0 && index < len(typed) {
+					next = append(next, typed[index])
+				}
+			}
+		}
+		current = next
+	}
+
+	return current, nil
+}
+
+func formatValue(value interface{}) string {
+	switch typed := value.(type) {
+	case string:
+		return typed
+	case nil:
+		return ""
+	case map[string]interface{}, []interface{}:
+		encoded, _ := jsonMarshal(typed)
+		return encoded
+	}
+
+	return fmt.Sprint(value)
+}
@@ -0,0 +1,112 @@
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+)
+
+const jsonPathTestData = `{
+	"kind": "List",
+	"items": [
+		{
+			"metadata": {
+				"name": "web",
+				"labels": {"app": "web", "app.kubernetes.io/name": "nginx", "a}b": "brace"}
+			},
+			"spec": {"replicas": 3, "ports": [{"port": 80}, {"port": 443}]}
+		},
+		{
+			"metadata": {"name": "worker", "labels": {"app": "worker"}},
+			"spec": {"replicas": 1, "ports": []}
+		}
+	]
+}`
+
+func TestJSONPath(t *testing.T) {
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonPathTestData), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		template string
+		want     string
+	}{
+		{`{.kind}`, "List"},
+		{`$.kind`, "$.kind"},
+		{`{$.kind}`, "List"},
+		{`kind: {.kind}!`, "kind: List!"},
+		{`{.items[0].metadata.name}`, "web"},
+		{`{.items[-1].metadata.name}`, "worker"},
+		{`{.items[5].metadata.name}`, ""},
+		{`{.items[*].metadata.name}`, "web worker"},
+		{`{.items[*].spec.replicas}`, "3 1"},
+		{`{.items[0].spec.ports[*].port}`, "80 443"},
+		{`{.items[0].spec.ports[1]}`, `{"port":443}`},
+		{`{.missing.field}`, ""},
+		{`{ .kind }`, "List"},
+		{`{.items[0].metadata.labels['app.kubernetes.io/name']}`, "nginx"},
+		{`{.items[0].metadata.labels["app.kubernetes.io/name"]}`, "nginx"},
+		{`{.items[0].metadata.labels['a}b']}`, "brace"},
+		{`{.items[0].metadata.labels["a}b"]}`, "brace"},
+		{`{"}"}{.kind}{"{"}`, "}List{"},
+		{`{"a\"}b"}`, `a"}b`},
+		{`{range .items[*]}{.metadata.name}{"\t"}{.spec.replicas}{"\n"}{end}`, "web\t3\nworker\t1\n"},
+		{`{range .items[*]}[{range .spec.ports[*]}{.port},{end}]{end}`, "[80,443,][]"},
+	}
+
+	for _, test := range tests {
+		parsed, err := parseJSONPath(test.template)
+		if err != nil {
+			t.Errorf("parseJSONPath(%q): %v", test.template, err)
+			continue
+		}
+
+		var output bytes.Buffer
+		if err := parsed.execute(&output, data); err != nil {
+			t.Errorf("execute(%q): %v", test.template, err)
+			continue
+		}
+
+		if output.String() != test.want {
+			t.Errorf("execute(%q) = %q, want %q", test.template, output.String(), test.want)
+		}
+	}
+}
+
+func TestJSONPathErrors(t *testing.T) {
+	for _, template := range []string{
+		`{.kind`,
+		`{.labels['a}b'`,
+		`{"unterminated}`,
+		`{kind}`,
+		`{.items..name}`,
+		`{.items[0}`,
+		`{.items[]}`,
+		`{.labels['app]}`,
+		`{.labels['app'x]}`,
+		`{"\q"}`,
+		`{range .items[*]}{.metadata.name}`,
+		`{.kind}{end}`,
+	} {
+		if _, err := parseJSONPath(template); err == nil {
+			t.Errorf("parseJSONPath(%q) succeeded, want an error", template)
+		}
+	}
+
+	var data interface{}
+	if err := json.Unmarshal([]byte(jsonPathTestData), &data); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := parseJSONPath(`{.items[first]}`)
+	if err != nil {
+		t.Fatalf("parseJSONPath: %v", err)
+	}
+
+	var output bytes.Buffer
+	if err := parsed.execute(&output, data); err == nil {
+		t.Error("execute of a non-numeric array index succeeded, want an error")
+	}
+}
@@ -0,0 +1,190 @@
+// Package output prints lists of Kubernetes objects in the formats
+// accepted by the -output flag: table, wide, json, yaml, name,
+// go-template=<template> and jsonpath=<template>.
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"text/template"
+
+	"gopkg.in/yaml.v2"
+)
+
+// Formats lists the accepted -output values, for help messages.
+const Formats = "table | wide | json | yaml | name | go-template=<template> | jsonpath=<template>"
+
+// List is a list of API objects of a single kind, along with their table
+// representation.
+type List struct {
+	kind          string
+	allNamespaces bool
+	headers       []string
+	wideHeaders   []string
+	objects       []interface{}
+	names         []string
+	rows          [][]string
+}
+
+// row holds the table cells of one object.
+type row struct {
+	namespace string
+	name      string
+	cells     []string
+	wideCells []string
+}
+
+func newList(kind string, allNamespaces bool, headers, wideHeaders []string) *List {
+	return &List{
+		kind:          kind,
+		allNamespaces: allNamespaces,
+		headers:       headers,
+		wideHeaders:   wideHeaders,
+		objects:       []interface{}{},
+	}
+}
+
+func (l *List) add(object interface{}, r row) {
+	cells := []string{}
+	if l.allNamespaces {
+		cells = append(cells, r.namespace)
+	}
+	cells = append(cells, r.name)
+	cells = append(cells, r.cells...)
+	cells = append(cells, r.wideCells...)
+
+	l.objects = append(l.objects, object)
+	l.names = append(l.names, r.name)
+	l.rows = append(l.rows, cells)
+}
+
+// ValidateFormat checks an -output value without printing anything.
+func ValidateFormat(format string) error {
+	name, argument := splitFormat(format)
+	switch name {
+	case "", "table", "wide", "json", "yaml", "name":
+		return nil
+	case "go-template", "template":
+		_, err := template.New("output").Parse(argument)
+		return err
+	case "jsonpath":
+		_, err := parseJSONPath(argument)
+		return err
+	}
+
+	return fmt.Errorf("unknown output format %q, must be one of: %s", format, Formats)
+}
+
+// Print writes the list to w in the given format.
+func Print(w io.Writer, format string, list *List) error {
+	name, argument := splitFormat(format)
+	switch name {
+	case "", "table":
+		return list.printTable(w, false)
+	case "wide":
+		return list.printTable(w, true)
+	case "name":
+		for _, objectName := range list.names {
+			fmt.Fprintf(w, "%s/%s\n", list.kind, objectName)
+		}
+		return nil
+	}
+
+	generic, err := list.generic()
+	if err != nil {
+		return err
+	}
+
+	switch name {
+	case "json":
+		encoded, err := json.MarshalIndent(generic, "", "    ")
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(w, string(encoded))
+		return err
+	case "yaml":
+		encoded, err := yaml.Marshal(generic)
+		if err != nil {
+			return err
+		}
+
+		_, err = w.Write(encoded)
+		return err
+	case "go-template", "template":
+		parsed, err := template.New("output").Parse(argument)
+		if err != nil {
+			return err
+		}
+
+		return parsed.Execute(w, generic)
+	case "jsonpath":
+		parsed, err := parseJSONPath(argument)
+		if err != nil {
+			return err
+		}
+
+		return parsed.execute(w, generic)
+	}
+
+	return fmt.Errorf("unknown output format %q, must be one of: %s", format, Formats)
+}
+
+func splitFormat(format string) (string, string) {
+	parts := strings.SplitN(format, "=", 2)
+	if len(parts) == 1 {
+		return parts[0], ""
+	}
+
+	return parts[0], parts[1]
+}
+
+func (l *List) printTable(w io.Writer, wide bool) error {
+	headers := []string{}
+	if l.allNamespaces {
+		headers = append(headers, "NAMESPACE")
+	}
+	headers = append(headers, "NAME")
+	headers = append(headers, l.headers...)
+
+	columns := len(headers)
+	if wide {
+		headers = append(headers, l.wideHeaders...)
+		columns = len(headers)
+	}
+
+	table := tabwriter.NewWriter(w, 0, 8, 3, ' ', 0)
+	fmt.Fprintln(table, strings.Join(headers, "\t"))
+	for _, cells := range l.rows {
+		fmt.Fprintln(table, strings.Join(cells[:columns], "\t"))
+	}
+
+	return table.Flush()
+}
+
+// generic returns the list as a kubectl style List object decoded into
+// maps and slices, the form templates and the YAML encoder work on.
+func (l *List) generic() (interface{}, error) {
+	listJSON, err := json.Marshal(map[string]interface{}{
+		"kind":       "List",
+		"apiVersion": "v1",
+		"metadata":   map[string]interface{}{},
+		"items":      l.objects,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var generic interface{}
+	err = json.Unmarshal(listJSON, &generic)
+	return generic, err
+}
+
+func jsonMarshal(value interface{}) (string, error) {
+	encoded, err := json.Marshal(value)
+	return string(encoded), err
+}
@@ -0,0 +1,345 @@
+package output
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	appsv1beta2 "k8s.io/api/apps/v1beta2"
+	autoscalingv1 "k8s.io/api/autoscaling/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	batchv2alpha1 "k8s.io/api/batch/v2alpha1"
+	apiv1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Deployments prepares deployments for printing. They keep the API
+// version they were listed with, as DeploymentOrchestrator.List sets it,
+// and are taken for apps/v1beta2 ones when it is unset.
+func Deployments(deployments []appsv1beta2.Deployment, allNamespaces bool) *List {
+	list := newList("deployment", allNamespaces,
+		[]string{"DESIRED", "CURRENT", "UP-TO-DATE", "AVAILABLE", "AGE"},
+		[]string{"CONTAINERS", "IMAGES", "SELECTOR"})
+
+	for _, deployment := range deployments {
+		deployment.Kind = "Deployment"
+		if deployment.APIVersion == "" {
+			deployment.APIVersion = "apps/v1beta2"
+		}
+
+		var desired int32 = 1
+		if deployment.Spec.Replicas != nil {
+			desired = *deployment.Spec.Replicas
+		}
+
+		containers, images := containerSummary(deployment.Spec.Template.Spec.Containers)
+		list.add(deployment, row{
+			namespace: deployment.Namespace,
+			name:      deployment.Name,
+			cells: []string{
+				strconv.Itoa(int(desired)),
+				strconv.Itoa(int(deployment.Status.Replicas)),
+				strconv.Itoa(int(deployment.Status.UpdatedReplicas)),
+				strconv.Itoa(int(deployment.Status.AvailableReplicas)),
+				age(deployment.CreationTimestamp),
+			},
+			wideCells: []string{containers, images, labelSelector(deployment.Spec.Selector)},
+		})
+	}
+
+	return list
+}
+
+// Services prepares services for printing.
+func Services(services []apiv1.Service, allNamespaces bool) *List {
+	list := newList("service", allNamespaces,
+		[]string{"TYPE", "CLUSTER-IP", "PORT(S)", "AGE"},
+		[]string{"SELECTOR"})
+
+	for _, service := range services {
+		service.Kind = "Service"
+		service.APIVersion = "v1"
+
+		ports := []string{}
+		for _, port := range service.Spec.Ports {
+			ports = append(ports, fmt.Sprintf("%d/%s", port.Port, port.Protocol))
+		}
+
+		list.add(service, row{
+			namespace: service.Namespace,
+			name:      service.Name,
+			cells: []string{
+				string(service.Spec.Type),
+				valueOrNone(service.Spec.ClusterIP),
+				valueOrNone(strings.Join(ports, ",")),
+				age(service.CreationTimestamp),
+			},
+			wideCells: []string{valueOrNone(joinLabels(service.Spec.Selector))},
+		})
+	}
+
+	return list
+}
+
+// Jobs prepares jobs for printing.
+func Jobs(jobs []batchv1.Job, allNamespaces bool) *List {
+	list := newList("job", allNamespaces,
+		[]string{"DESIRED", "SUCCESSFUL", "AGE"},
+		[]string{"CONTAINERS", "IMAGES", "SELECTOR"})
+
+	for _, job := range jobs {
+		job.Kind = "Job"
+		job.APIVersion = "batch/v1"
+
+		desired := "<none>"
+		if job.Spec.Completions != nil {
+			desired = strconv.Itoa(int(*job.Spec.Completions))
+		}
+
+		containers, images := containerSummary(job.Spec.Template.Spec.Containers)
+		list.add(job, row{
+			namespace: job.Namespace,
+			name:      job.Name,
+			cells: []string{
+				desired,
+				strconv.Itoa(int(job.Status.Succeeded)),
+				age(job.CreationTimestamp),
+			},
+			wideCells: []string{containers, images, labelSelector(job.Spec.Selector)},
+		})
+	}
+
+	return list
+}
+
+// CronJobs prepares cron jobs for printing.
+func CronJobs(cronJobs []batchv2alpha1.CronJob, allNamespaces bool) *List {
+	list := newList("cronjob", allNamespaces,
+		[]string{"SCHEDULE", "SUSPEND", "ACTIVE", "LAST SCHEDULE", "AGE"},
+		[]string{"CONTAINERS", "IMAGES"})
+
+	for _, cronJob := range cronJobs {
+		cronJob.Kind = "CronJob"
+		cronJob.APIVersion = "batch/v2alpha1"
+
+		suspend := cronJob.Spec.Suspend != nil && *cronJob.Spec.Suspend
+
+		lastSchedule := "<none>"
+		if cronJob.Status.LastScheduleTime != nil {
+			lastSchedule = age(*cronJob.Status.LastScheduleTime)
+		}
+
+		containers, images := containerSummary(cronJob.Spec.JobTemplate.Spec.Template.Spec.Containers)
+		list.add(cronJob, row{
+			namespace: cronJob.Namespace,
+			name:      cronJob.Name,
+			cells: []string{
+				cronJob.Spec.Schedule,
+				strconv.FormatBool(suspend),
+				strconv.Itoa(len(cronJob.Status.Active)),
+				lastSchedule,
+				age(cronJob.CreationTimestamp),
+			},
+			wideCells: []string{containers, images},
+		})
+	}
+
+	return list
+}
+
+// Autoscalers prepares horizontal pod autoscalers for printing. CURRENT
+// and DESIRED are the replica counts the autoscaler last saw and asked
+// for, and TARGETS the CPU usage against its target.
+func Autoscalers(autoscalers []autoscalingv1.HorizontalPodAutoscaler, allNamespaces bool) *List {
+	list := newList("horizontalpodautoscaler", allNamespaces,
+		[]string{"REFERENCE", "TARGETS", "MINPODS", "MAXPODS", "CURRENT", "DESIRED", "AGE"},
+		[]string{"LAST SCALE"})
+
+	for _, autoscaler := range autoscalers {
+		autoscaler.Kind = "HorizontalPodAutoscaler"
+		autoscaler.APIVersion = "autoscaling/v1"
+
+		targets := "<none>"
+		if autoscaler.Spec.TargetCPUUtilizationPercentage != nil {
+			current := "<unknown>"
+			if autoscaler.Status.CurrentCPUUtilizationPercentage != nil {
+				current = fmt.Sprintf("%d%%", *autoscaler.Status.CurrentCPUUtilizationPercentage)
+			}
+			targets = fmt.Sprintf("%s/%d%%", current, *autoscaler.Spec.TargetCPUUtilizationPercentage)
+		}
+
+		var minReplicas int32 = 1
+		if autoscaler.Spec.MinReplicas != nil {
+			minReplicas = *autoscaler.Spec.MinReplicas
+		}
+
+		lastScale := "<none>"
+		if autoscaler.Status.LastScaleTime != nil {
+			lastScale = age(*autoscaler.Status.LastScaleTime)
+		}
+
+		reference := autoscaler.Spec.ScaleTargetRef
+		list.add(autoscaler, row{
+			namespace: autoscaler.Namespace,
+			name:      autoscaler.Name,
+			cells: []string{
+				reference.Kind + "/" + reference.Name,
+				targets,
+				strconv.Itoa(int(minReplicas)),
+				strconv.Itoa(int(autoscaler.Spec.MaxReplicas)),
+				strconv.Itoa(int(autoscaler.Status.CurrentReplicas)),
+				strconv.Itoa(int(autoscaler.Status.DesiredReplicas)),
+				age(autoscaler.CreationTimestamp),
+			},
+			wideCells: []string{lastScale},
+		})
+	}
+
+	return list
+}
+
+// Pods prepares pods for printing. STATUS is the reason a container is
+// waiting or terminated, such as CrashLoopBackOff, when there is one, and
+// the pod phase otherwise.
+func Pods(pods []apiv1.Pod, allNamespaces bool) *List {
+	list := newList("pod", allNamespaces,
+		[]string{"READY", "STATUS", "RESTARTS", "LAST TERMINATION", "IP", "NODE", "AGE"},
+		[]string{"CONTAINERS", "IMAGES"})
+
+	for _, pod := range pods {
+		pod.Kind = "Pod"
+		pod.APIVersion = "v1"
+
+		var ready, restarts int32
+		for _, status := range pod.Status.ContainerStatuses {
+			if status.Ready {
+				ready++
+			}
+			restarts += status.RestartCount
+		}
+
+		containers, images := containerSummary(pod.Spec.Containers)
+		list.add(pod, row{
+			namespace: pod.Namespace,
+			name:      pod.Name,
+			cells: []string{
+				fmt.Sprintf("%d/%d", ready, len(pod.Spec.Containers)),
+				podStatus(pod),
+				strconv.Itoa(int(restarts)),
+				lastTermination(pod.Status.ContainerStatuses),
+				valueOrNone(pod.Status.PodIP),
+				valueOrNone(pod.Spec.NodeName),
+				age(pod.CreationTimestamp),
+			},
+			wideCells: []string{containers, images},
+		})
+	}
+
+	return list
+}
+
+// podStatus sums up the state of a pod the way kubectl does: the reason
+// of the first container that is waiting or terminated abnormally, or
+// the pod phase.
+func podStatus(pod apiv1.Pod) string {
+	if pod.DeletionTimestamp != nil {
+		return "Terminating"
+	}
+
+	statuses := append(append([]apiv1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		switch {
+		case status.State.Waiting != nil && status.State.Waiting.Reason != "" && status.State.Waiting.Reason != "PodInitializing":
+			return status.State.Waiting.Reason
+		case status.State.Terminated != nil && status.State.Terminated.ExitCode != 0:
+			return valueOrNone(status.State.Terminated.Reason)
+		}
+	}
+
+	if pod.Status.Reason != "" {
+		return pod.Status.Reason
+	}
+
+	return string(pod.Status.Phase)
+}
+
+// lastTermination describes the most recent termination of the pod's
+// containers, e.g. "Error (exit 1) 5m ago".
+func lastTermination(statuses []apiv1.ContainerStatus) string {
+	var last *apiv1.ContainerStateTerminated
+	for _, status := range statuses {
+		for _, terminated := range []*apiv1.ContainerStateTerminated{status.LastTerminationState.Terminated, status.State.Terminated} {
+			if terminated != nil && (last == nil || last.FinishedAt.Before(&terminated.FinishedAt)) {
+				last = terminated
+			}
+		}
+	}
+
+	if last == nil {
+		return "<none>"
+	}
+
+	return fmt.Sprintf("%s (exit %d) %s ago", valueOrNone(last.Reason), last.ExitCode, age(last.FinishedAt))
+}
+
+func containerSummary(containers []apiv1.Container) (string, string) {
+	names := []string{}
+	images := []string{}
+	for _, container := range containers {
+		names = append(names, container.Name)
+		images = append(images, container.Image)
+	}
+
+	return strings.Join(names, ","), strings.Join(images, ",")
+}
+
+func labelSelector(selector *metav1.LabelSelector) string {
+	if selector == nil {
+		return "<none>"
+	}
+
+	return valueOrNone(metav1.FormatLabelSelector(selector))
+}
+
+func joinLabels(labels map[string]string) string {
+	pairs := []string{}
+	for key, value := range labels {
+		pairs = append(pairs, key+"="+value)
+	}
+
+	sort.Strings(pairs)
+	return strings.Join(pairs, ",")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "<none>"
+	}
+
+	return value
+}
+
+// age formats the time elapsed since the timestamp the way kubectl does,
+// with the largest unit that fits.
+func age(timestamp metav1.Time) string {
+	if timestamp.IsZero() {
+		return "<unknown>"
+	}
+
+	return shortDuration(time.Since(timestamp.Time))
+}
+
+func shortDuration(elapsed time.Duration) string {
+	switch {
+	case elapsed < time.Minute:
+		return fmt.Sprintf("%ds", int(elapsed.Seconds()))
+	case elapsed < time.Hour:
+		return fmt.Sprintf("%dm", int(elapsed.Minutes()))
+	case elapsed < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(elapsed.Hours()))
+	}
+
+	return fmt.Sprintf("%dd", int(elapsed.Hours()/24))
+}